@@ -0,0 +1,86 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Manipulate node values as YottaDB bit strings, as used by M's $ZBIT* functions.
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidBitString is returned when the value of a node is not a valid YottaDB bit string.
+var ErrInvalidBitString = errors.New("YDB: invalid bit string")
+
+// A YottaDB bit string, as created by M's `$ZBITSTR()`, is a byte giving the number of unused bits (0-7) at the end of
+// the last byte, followed by the bits packed into bytes with bit 1 in the most significant bit of the first byte.
+
+// bitString splits bit string s into its packed bits and its length in bits.
+func bitString(s string) ([]byte, int, error) {
+	if s == "" || s[0] > 7 || len(s) == 1 && s[0] != 0 {
+		return nil, 0, fmt.Errorf("%w: %q", ErrInvalidBitString, s)
+	}
+	return []byte(s[1:]), (len(s)-1)*8 - int(s[0]), nil
+}
+
+// GetBit returns bit number pos of the bit string stored in n, counting from 1, like M's `$ZBITGET()`.
+// Unlike $ZBITGET, bits beyond the end of the bit string, or of a node with no value, are returned as false
+// so that a bit string may be used as a sparse bit map.
+func (n *Node) GetBit(pos int) (bool, error) {
+	if pos < 1 {
+		return false, fmt.Errorf("YDB: bit position %d is less than 1", pos)
+	}
+	val, err := n.Get("\x00")
+	if err != nil {
+		return false, err
+	}
+	bits, length, err := bitString(val)
+	if err != nil || pos > length {
+		return false, err
+	}
+	return bits[(pos-1)/8]&(0x80>>((pos-1)%8)) != 0, nil
+}
+
+// SetBit sets bit number pos of the bit string stored in n, counting from 1, to on, like M's `$ZBITSET()`.
+// Unlike $ZBITSET, setting a bit beyond the end of the bit string extends it with zero bits, and a node with no value
+// is treated as an empty bit string. The read-modify-write is done within a transaction.
+func (n *Node) SetBit(pos int, on bool) error {
+	if pos < 1 {
+		return fmt.Errorf("YDB: bit position %d is less than 1", pos)
+	}
+	return n.conn.Transaction(func(conn *Conn) error {
+		val, err := n.Get("\x00")
+		if err != nil {
+			return err
+		}
+		bits, length, err := bitString(val)
+		if err != nil {
+			return err
+		}
+		if pos > length {
+			if !on {
+				return nil // bits beyond the end are already clear
+			}
+			length = pos
+			bits = append(bits, make([]byte, (length+7)/8-len(bits))...)
+		}
+		mask := byte(0x80 >> ((pos - 1) % 8))
+		if on {
+			bits[(pos-1)/8] |= mask
+		} else {
+			bits[(pos-1)/8] &^= mask
+		}
+		unused := byte(len(bits)*8 - length)
+		return n.Set(string(unused) + string(bits))
+	})
+}
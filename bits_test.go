@@ -0,0 +1,64 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test setting and getting bits of YottaDB bit strings.
+func TestBits(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^bits")
+	n.Delete()
+	for _, pos := range []int{1, 10} {
+		if err := n.SetBit(pos, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 10 bits in 2 bytes leaves 6 unused bits: 1000 0000, 01xx xxxx
+	if val := n.MustGet(); val != "\x06\x80\x40" {
+		t.Errorf("got %q, want %q", val, "\x06\x80\x40")
+	}
+	for pos, want := range map[int]bool{1: true, 2: false, 9: false, 10: true, 11: false, 1000: false} {
+		if got, err := n.GetBit(pos); err != nil || got != want {
+			t.Errorf("bit %d: got %v, %v, want %v", pos, got, err, want)
+		}
+	}
+	n.SetBit(1, false)
+	n.SetBit(50, false) // clearing beyond the end leaves the length unchanged
+	if val := n.MustGet(); val != "\x06\x00\x40" {
+		t.Errorf("got %q, want %q", val, "\x06\x00\x40")
+	}
+
+	// A bit string as created by M's $ZBITSET($ZBITSTR(5),1,1) and $ZBITSET(...,3,1)
+	n.Set("\x03\xa0")
+	for pos, want := range map[int]bool{1: true, 2: false, 3: true, 4: false, 5: false} {
+		if got, _ := n.GetBit(pos); got != want {
+			t.Errorf("bit %d: got %v, want %v", pos, got, want)
+		}
+	}
+	n.SetBit(8, true)
+	if val := n.MustGet(); val != "\x00\xa1" {
+		t.Errorf("got %q, want %q", val, "\x00\xa1")
+	}
+
+	if _, err := n.GetBit(0); err == nil {
+		t.Errorf("got no error for bit 0")
+	}
+	n.Set("not a bit string")
+	if err := n.SetBit(1, true); !errors.Is(err, ErrInvalidBitString) {
+		t.Errorf("got error %v, want %v", err, ErrInvalidBitString)
+	}
+}
@@ -0,0 +1,96 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Build nodes from subscripts that are accumulated programmatically.
+
+package yottadb
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// NodeBuilder accumulates the subscripts of a node so that its C storage is allocated only once, by Node().
+// This is more efficient than building a deep path with chained Child() calls, each of which allocates a new Node.
+type NodeBuilder struct {
+	conn       *Conn
+	varname    string
+	subscripts []string
+}
+
+// Builder returns a NodeBuilder for a node of variable varname.
+// For example: `conn.Builder("^person").Sub("name").SubInt(3).Node()`.
+func (conn *Conn) Builder(varname string) *NodeBuilder {
+	return &NodeBuilder{conn: conn, varname: varname}
+}
+
+// Sub appends subscript sub to the node being built.
+func (b *NodeBuilder) Sub(sub string) *NodeBuilder {
+	b.subscripts = append(b.subscripts, sub)
+	return b
+}
+
+// SubInt appends integer subscript sub to the node being built.
+func (b *NodeBuilder) SubInt(sub int) *NodeBuilder {
+	return b.Sub(strconv.Itoa(sub))
+}
+
+// Node returns a new Node with the varname and subscripts accumulated so far.
+// The builder may continue to be used afterwards to build deeper nodes.
+func (b *NodeBuilder) Node() *Node {
+	return b.conn.Node(b.varname, b.subscripts...)
+}
+
+// PadInt formats v as a decimal subscript of at least width digits, padded with leading zeros, such as "0042" for
+// PadInt(42, 4). Values with more digits than width are not truncated.
+// YottaDB collates canonical numbers numerically before all strings, but a subscript with leading zeros is not
+// canonical, so it collates as a string, byte by byte. Padded subscripts of the same width therefore collate in numeric
+// order, which is useful for keys that must also sort correctly as strings, such as time buckets like "2025"/"03".
+// Choose width larger than the number of digits in any value so that every subscript has a leading zero: a padded
+// value such as PadInt(42, 2) has none, so it is a canonical number and collates before all the padded strings.
+// Negative values are padded after the sign but do not collate in numeric order.
+func PadInt(v, width int) string {
+	return padDigits(strconv.Itoa(v), width)
+}
+
+// padDigits pads the digits of decimal integer s with leading zeros, after any sign, to at least width digits.
+func padDigits(s string, width int) string {
+	digits := strings.TrimLeft(s, "+-")
+	if len(digits) >= width {
+		return s
+	}
+	return s[:len(s)-len(digits)] + strings.Repeat("0", width-len(digits)) + digits
+}
+
+// NodePadded creates a Node like Node(), but formats subscripts from values of any type: integers subs[i] are padded to
+// widths[i] digits with PadInt() if widths[i] > 0, strings are used as they are, and other values are formatted with
+// fmt.Sprint(). Missing widths are treated as 0. See PadInt() for how padded subscripts collate.
+// For example, `conn.NodePadded("^hits", []int{0, 5, 3}, "day", 2025, 7)` is ^hits("day")("02025")("007").
+func (conn *Conn) NodePadded(varname string, widths []int, subs ...any) *Node {
+	subscripts := make([]string, len(subs))
+	for i, sub := range subs {
+		width := 0
+		if i < len(widths) {
+			width = widths[i]
+		}
+		switch v := sub.(type) {
+		case string:
+			subscripts[i] = v
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			subscripts[i] = padDigits(fmt.Sprint(v), width)
+		default:
+			subscripts[i] = fmt.Sprint(v)
+		}
+	}
+	return conn.Node(varname, subscripts...)
+}
@@ -0,0 +1,91 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test building nodes with NodeBuilder.
+func TestBuilder(t *testing.T) {
+	conn := NewConn()
+	b := conn.Builder("^build").Sub("a").SubInt(-2)
+	got := b.Node().String()
+	want := `^build("a")("-2")`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	got = b.Sub("").SubInt(10).Node().String()
+	want = `^build("a")("-2")("")("10")`
+	if got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if got := conn.Builder("var").Node().String(); got != "var" {
+		t.Errorf("got %s, want %s", got, "var")
+	}
+	n := conn.Builder("^build").Sub("x").Node()
+	n.Set("value")
+	if val, _ := conn.Node("^build", "x").Get(); val != "value" {
+		t.Errorf("got %s, want %s", val, "value")
+	}
+}
+
+// Test padding numeric subscripts so that they collate in numeric order as strings.
+func TestPadded(t *testing.T) {
+	for _, test := range []struct {
+		v, width int
+		want     string
+	}{{42, 4, "0042"}, {0, 3, "000"}, {12345, 3, "12345"}, {-7, 3, "-007"}, {5, 0, "5"}} {
+		if got := PadInt(test.v, test.width); got != test.want {
+			t.Errorf("PadInt(%d, %d) got %s, want %s", test.v, test.width, got, test.want)
+		}
+	}
+
+	conn := NewConn()
+	n := conn.NodePadded("^padded", []int{0, 5, 3}, "day", 2025, uint8(7))
+	if want := `^padded("day")("02025")("007")`; n.String() != want {
+		t.Errorf("got %s, want %s", n, want)
+	}
+	if want := `^padded("x")("1.5")("true")`; conn.NodePadded("^padded", nil, "x", 1.5, true).String() != want {
+		t.Errorf("got %s, want %s", conn.NodePadded("^padded", nil, "x", 1.5, true), want)
+	}
+
+	// Padded subscripts iterate in numeric order, unlike a mix of numbers and strings
+	root := conn.Node("^padded")
+	root.DeleteTree()
+	values := []int{300, 7, 4000, 20, 1}
+	for _, v := range values {
+		conn.NodePadded("^padded", []int{5}, v).Set("")
+	}
+	got := slices.Collect(root.ChildSubscripts())
+	if want := []string{"00001", "00007", "00020", "00300", "04000"}; !slices.Equal(got, want) {
+		t.Errorf("got order %q, want %q", got, want)
+	}
+}
+
+// Benchmark building a 6-level path with NodeBuilder.
+func BenchmarkBuilder(b *testing.B) {
+	conn := NewConn()
+	for b.Loop() {
+		conn.Builder("^var").Sub("a").Sub("b").SubInt(1).Sub("c").SubInt(2).Sub("d").Node()
+	}
+}
+
+// Benchmark building the same 6-level path with chained Child() calls, for comparison with BenchmarkBuilder.
+func BenchmarkBuilderChild(b *testing.B) {
+	conn := NewConn()
+	for b.Loop() {
+		conn.Node("^var").Child("a").Child("b").Child("1").Child("c").Child("2").Child("d")
+	}
+}
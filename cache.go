@@ -0,0 +1,125 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Cache the values of read-mostly nodes.
+
+package yottadb
+
+import (
+	"time"
+)
+
+// CachedNode is a Node whose Get() keeps the value it reads for a time-to-live and returns that cached value without
+// accessing the database until the time-to-live has passed. This suits read-mostly hot nodes, at the cost of staleness:
+// for up to the time-to-live after reading, Get() does not see changes made by other processes, goroutines,
+// connections, or even other Node objects for the same database node.
+// Only updates made through the CachedNode itself (Set, Delete, DeleteTree, Increment and IncrementDiscard) discard
+// the cached value, once the transaction commits if they are made within one. Within a transaction Get() always reads the database and does not fill the cache, since the value
+// it reads is not committed and may be rolled back. Errors are not cached.
+// The cache is kept apart from the Node, which remains immutable. Like its Node, a CachedNode uses the Node's Conn, so
+// each goroutine needs its own CachedNode, made from a Node of the goroutine's own Conn.
+type CachedNode struct {
+	*Node
+	ttl   time.Duration
+	value string    // value last read by Get()
+	end   time.Time // time when value expires; zero if nothing is cached
+}
+
+// Cached returns a CachedNode for n that caches its value for ttl. A mutable n is copied first.
+func (n *Node) Cached(ttl time.Duration) *CachedNode {
+	if n.n.mutable != 0 {
+		n = n.Copy()
+	}
+	return &CachedNode{Node: n, ttl: ttl}
+}
+
+// CachedGet returns the value of n like Get(), but caches the value it reads for ttl and returns that cached value
+// without accessing the database until ttl has passed, with the same staleness as a CachedNode (see Cached()). The
+// cache is kept with n, so later calls of CachedGet on n share it; ttl applies when the value is next read.
+// Updates made through n do not discard the cached value: use Cached() for a CachedNode whose updates do.
+// Mutable nodes, such as those yielded by iterators, never cache and always read the database.
+func (n *Node) CachedGet(ttl time.Duration) (string, error) {
+	if n.n.mutable != 0 {
+		return n.Get()
+	}
+	if n.cache == nil {
+		n.cache = &CachedNode{Node: n}
+	}
+	n.cache.ttl = ttl
+	return n.cache.Get()
+}
+
+// Get returns the cached value of c if it has not expired, or else reads the value of c like Node.Get() and, outside a
+// transaction, caches it.
+func (c *CachedNode) Get() (string, error) {
+	if c.conn.tpDepth > 0 {
+		return c.Node.Get()
+	}
+	if time.Now().Before(c.end) {
+		return c.value, nil
+	}
+	val, err := c.Node.Get()
+	if err != nil {
+		return val, err
+	}
+	c.value, c.end = val, time.Now().Add(c.ttl)
+	return val, nil
+}
+
+// invalidate discards the cached value of c.
+func (c *CachedNode) invalidate() {
+	c.end = time.Time{}
+}
+
+// updated discards the cached value of c after an update through c returned err. The value is discarded only once the
+// update is made, rather than before it, lest a Get() in between cache the old value again. Within a transaction that
+// is once the transaction commits (see Defer()), since until then Get() reads the database anyway.
+func (c *CachedNode) updated(err error) {
+	if err == nil {
+		c.conn.Defer(c.invalidate)
+	}
+}
+
+// Set sets the value of c like Node.Set() and discards the cached value.
+func (c *CachedNode) Set(val string) error {
+	err := c.Node.Set(val)
+	c.updated(err)
+	return err
+}
+
+// Delete deletes the value of c like Node.Delete() and discards the cached value.
+func (c *CachedNode) Delete() error {
+	err := c.Node.Delete()
+	c.updated(err)
+	return err
+}
+
+// DeleteTree deletes c and its subtree like Node.DeleteTree() and discards the cached value.
+func (c *CachedNode) DeleteTree() error {
+	err := c.Node.DeleteTree()
+	c.updated(err)
+	return err
+}
+
+// Increment increments the value of c like Node.Increment() and discards the cached value.
+func (c *CachedNode) Increment(amount string) (string, error) {
+	val, err := c.Node.Increment(amount)
+	c.updated(err)
+	return val, err
+}
+
+// IncrementDiscard increments the value of c like Node.IncrementDiscard() and discards the cached value.
+func (c *CachedNode) IncrementDiscard(amount string) error {
+	err := c.Node.IncrementDiscard(amount)
+	c.updated(err)
+	return err
+}
@@ -0,0 +1,92 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"testing"
+	"time"
+)
+
+// Test that CachedNode returns a stale value within its time-to-live and refreshes it afterwards.
+func TestCachedNode(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^cachedget").Cached(50 * time.Millisecond)
+	other := NewConn().Node("^cachedget")
+	n.Set("first")
+	if val, err := n.Get(); err != nil || val != "first" {
+		t.Fatalf("got %q, %v, want %q", val, err, "first")
+	}
+	other.Set("second")
+	if val, _ := n.Get(); val != "first" {
+		t.Errorf("got %s within ttl, want stale value %s", val, "first")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if val, _ := n.Get(); val != "second" {
+		t.Errorf("got %s after ttl, want refreshed value %s", val, "second")
+	}
+	n.Set("third")
+	if val, _ := n.Get(); val != "third" {
+		t.Errorf("got %s after setting through the node, want %s", val, "third")
+	}
+
+	// A value read within a transaction that is rolled back must not be cached
+	n.Set("committed")
+	err := conn.Transaction(func(conn *Conn) error {
+		other.on(conn).Set("rolled back")
+		if val, _ := n.Get(); val != "rolled back" {
+			t.Errorf("got %s within the transaction, want %s", val, "rolled back")
+		}
+		return ErrRollback
+	})
+	if err != ErrRollback {
+		t.Fatalf("got error %v, want ErrRollback", err)
+	}
+	if val, _ := n.Get(); val != "committed" {
+		t.Errorf("got %s after rollback, want %s", val, "committed")
+	}
+
+	// An update through n within a transaction discards the cached value once it commits
+	err = conn.Transaction(func(conn *Conn) error {
+		return n.Set("updated")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := n.Get(); val != "updated" {
+		t.Errorf("got %s after a committed update, want %s", val, "updated")
+	}
+}
+
+// Test that CachedGet returns a stale value within its time-to-live and refreshes it afterwards.
+func TestCachedGet(t *testing.T) {
+	n := NewConn().Node("^cachedget", "plain")
+	other := NewConn().Node("^cachedget", "plain")
+	n.Set("first")
+	if val, err := n.CachedGet(50 * time.Millisecond); err != nil || val != "first" {
+		t.Fatalf("got %q, %v, want %q", val, err, "first")
+	}
+	other.Set("second")
+	if val, _ := n.CachedGet(50 * time.Millisecond); val != "first" {
+		t.Errorf("got %s within ttl, want stale value %s", val, "first")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if val, _ := n.CachedGet(50 * time.Millisecond); val != "second" {
+		t.Errorf("got %s after ttl, want refreshed value %s", val, "second")
+	}
+	for child := range n.conn.Node("^cachedget").Children() {
+		other.Set("third")
+		if val, _ := child.CachedGet(time.Hour); val != "third" {
+			t.Errorf("got %s from a mutable node, want uncached value %s", val, "third")
+		}
+	}
+}
@@ -0,0 +1,209 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Call M routines with ydb_ci_t() for operations that the SimpleAPI lacks
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unsafe"
+)
+
+/* #include <stdlib.h>
+#include "libyottadb.h"
+#include "yottadb.h"
+
+// cgo cannot call variadic C functions, so wrap the calls to ydb_ci_t() made by this package.
+static int ydbgo_ci_flush(uint64_t tptoken, ydb_buffer_t *errstr) {
+	return ydb_ci_t(tptoken, errstr, "ydbgoFlush");
+}
+static int ydbgo_ci_ztrigger(uint64_t tptoken, ydb_buffer_t *errstr, ydb_int_t *ret, char *op, char *arg, char *file) {
+	return ydb_ci_t(tptoken, errstr, "ydbgoZtrigger", ret, op, arg, file);
+}
+*/
+import "C"
+
+// callInRoutine is the M routine %ydbgo called by this package, which must be saved in file _ydbgo.m.
+const callInRoutine = `%ydbgo	; M routines called by the YottaDB Go wrapper
+	quit
+flush()	; write buffered journal and database updates to their files
+	view "JNLFLUSH"
+	view "FLUSH"
+	quit
+ztrigger(op,arg,file)	; return $ztrigger(op,arg), writing its output to file
+	new io,ok
+	set io=$io
+	open file:newversion
+	use file
+	set ok=$ztrigger(op,arg)
+	use io
+	close file
+	quit ok
+`
+
+// callInTable is the call-in table that maps the names used with ydb_ci_t() to the labels of %ydbgo.
+const callInTable = `ydbgoFlush: void flush^%ydbgo()
+ydbgoZtrigger: ydb_int_t* ztrigger^%ydbgo(I:ydb_char_t*,I:ydb_char_t*,I:ydb_char_t*)
+`
+
+// callIns holds this package's call-in table, which is opened once per process by the first callIn().
+var callIns struct {
+	once   sync.Once
+	mutex  sync.Mutex // serialises switching to the table, which is shared by the process
+	handle C.uintptr_t
+	err    error
+}
+
+// openCallIns writes callInRoutine and callInTable to a new temporary directory, prepends it to $ZROUTINES so that
+// YottaDB finds and compiles the routine, and opens the table, once per process. The directory remains until removed
+// by the operating system since the routine's object file must remain available to the process.
+func (conn *Conn) openCallIns() error {
+	callIns.once.Do(func() {
+		dir, err := os.MkdirTemp("", "ydbgo")
+		if err != nil {
+			callIns.err = err
+			return
+		}
+		table := filepath.Join(dir, "ydbgo.ci")
+		if err := os.WriteFile(filepath.Join(dir, "_ydbgo.m"), []byte(callInRoutine), 0644); err != nil {
+			callIns.err = err
+			return
+		}
+		if err := os.WriteFile(table, []byte(callInTable), 0644); err != nil {
+			callIns.err = err
+			return
+		}
+		routines := conn.Node("$ZROUTINES")
+		old, err := routines.Get()
+		if err == nil {
+			err = routines.setRaw(strings.TrimSpace(dir + " " + old))
+		}
+		if err != nil {
+			callIns.err = err
+			return
+		}
+		cTable := C.CString(table)
+		defer C.free(unsafe.Pointer(cTable))
+		ret := C.ydb_ci_tab_open_t(conn.c.tptoken, &conn.c.errstr, cTable, &callIns.handle)
+		callIns.err = conn.Error(ret)
+	})
+	return callIns.err
+}
+
+// callIn runs call, which must make a call-in listed in callInTable, with this package's call-in table made current.
+// The previous call-in table is restored afterwards, so that call-ins made by the application are not affected.
+// On first use, the M routine and call-in table are written to a temporary directory that is prepended to $ZROUTINES.
+func (conn *Conn) callIn(call func(*C.conn) C.int) error {
+	if err := conn.openCallIns(); err != nil {
+		return err
+	}
+	callIns.mutex.Lock()
+	defer callIns.mutex.Unlock()
+	var old C.uintptr_t
+	if ret := C.ydb_ci_tab_switch_t(conn.c.tptoken, &conn.c.errstr, callIns.handle, &old); ret != C.YDB_OK {
+		return conn.Error(ret)
+	}
+	err := conn.Error(call(conn.c))
+	if ret := C.ydb_ci_tab_switch_t(conn.c.tptoken, &conn.c.errstr, old, &old); ret != C.YDB_OK && err == nil {
+		err = conn.Error(ret)
+	}
+	return err
+}
+
+// Flush writes the buffered journal and database updates of all regions to the journal and database files, like M's
+// `VIEW "JNLFLUSH"` and `VIEW "FLUSH"`, so that an application checkpoint does not depend on YottaDB's periodic flush.
+// Whether the files then reach stable storage depends on the operating system and file system: journals opened with
+// sync_io are written through, but otherwise the data may still be lost if the machine (rather than the process) fails.
+// Since the SimpleAPI has no flush, Flush calls an M routine, which on first use is written to a temporary directory
+// that is prepended to $ZROUTINES for the rest of the process.
+func (conn *Conn) Flush() error {
+	return conn.callIn(func(c *C.conn) C.int {
+		return C.ydbgo_ci_flush(c.tptoken, &c.errstr)
+	})
+}
+
+// ErrInvalidTrigger is returned when a trigger definition or selection is malformed or is rejected by YottaDB.
+var ErrInvalidTrigger = errors.New("YDB: invalid trigger")
+
+// ztrigger calls M's $ZTRIGGER(op,arg) and returns whether it succeeded and the messages it wrote to the current device.
+func (conn *Conn) ztrigger(op, arg string) (bool, string, error) {
+	file, err := os.CreateTemp("", "ydbgo-ztrigger")
+	if err != nil {
+		return false, "", err
+	}
+	file.Close()
+	defer os.Remove(file.Name())
+	cOp, cArg, cFile := C.CString(op), C.CString(arg), C.CString(file.Name())
+	defer C.free(unsafe.Pointer(cOp))
+	defer C.free(unsafe.Pointer(cArg))
+	defer C.free(unsafe.Pointer(cFile))
+	var ok C.ydb_int_t
+	err = conn.callIn(func(c *C.conn) C.int {
+		return C.ydbgo_ci_ztrigger(c.tptoken, &c.errstr, &ok, cOp, cArg, cFile)
+	})
+	if err != nil {
+		return false, "", err
+	}
+	out, err := os.ReadFile(file.Name())
+	return ok != 0, string(out), err
+}
+
+// SetTrigger adds or deletes database triggers like M's $ZTRIGGER("ITEM",definition). The definition is one line of a
+// trigger file: "+^global -commands=S -xecute=..." adds a trigger, and "-name" or "-^global ..." deletes triggers.
+// If YottaDB rejects the definition, SetTrigger returns ErrInvalidTrigger with the messages that YottaDB reported.
+// Like Flush, SetTrigger calls an M routine, since triggers are stored in ^#t, which may not be updated directly.
+func (conn *Conn) SetTrigger(definition string) error {
+	if conn.probing {
+		return conn.probeWrite()
+	}
+	if !strings.HasPrefix(definition, "+^") && !strings.HasPrefix(definition, "-") || strings.Contains(definition, "\n") {
+		return fmt.Errorf("%w: definition %q must be one line starting with + or -", ErrInvalidTrigger, definition)
+	}
+	ok, out, err := conn.ztrigger("ITEM", definition)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrInvalidTrigger, strings.TrimSpace(out))
+	}
+	return nil
+}
+
+// ListTriggers returns the definitions of the triggers on global (such as "^account"), or on all globals if global is
+// "*", like M's $ZTRIGGER("SELECT",global). Each definition is in the format accepted by SetTrigger(), but YottaDB
+// may order its qualifiers differently from the definition that added it.
+func (conn *Conn) ListTriggers(global string) ([]string, error) {
+	if !strings.HasPrefix(global, "^") && global != "*" {
+		return nil, fmt.Errorf("%w: global %q must start with ^ or be *", ErrInvalidTrigger, global)
+	}
+	ok, out, err := conn.ztrigger("SELECT", global)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTrigger, strings.TrimSpace(out))
+	}
+	var triggers []string
+	for line := range strings.Lines(out) {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, ";") {
+			triggers = append(triggers, line)
+		}
+	}
+	return triggers, nil
+}
@@ -0,0 +1,117 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test that updates flushed by a process that is then killed are read by a new process.
+func TestFlush(t *testing.T) {
+	val := strconv.Itoa(os.Getpid())
+	t.Cleanup(func() { NewConn().Node("^flushtest").Delete() })
+	writer := exec.Command(os.Args[0], "-test.run=^TestHelperFlush$")
+	writer.Env = append(os.Environ(), "YDBGO_FLUSH=write,"+val)
+	stdin, err := writer.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	stdout, err := writer.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := writer.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	writer.Process.Kill()
+	writer.Wait()
+	if err != nil || line != "flushed\n" {
+		t.Fatalf("flushing process failed: %q, %v", line, err)
+	}
+	reader := exec.Command(os.Args[0], "-test.run=^TestHelperFlush$")
+	reader.Env = append(os.Environ(), "YDBGO_FLUSH=read")
+	out, err := reader.CombinedOutput()
+	if err != nil {
+		t.Fatalf("reading process failed: %v\n%s", err, out)
+	}
+	if !strings.HasPrefix(string(out), "value="+val+"\n") {
+		t.Errorf("read %q after the flushing process was killed, want value=%s", out, val)
+	}
+}
+
+// TestHelperFlush is not a real test: it is run as a subprocess by TestFlush() to set and flush a node, then wait to be
+// killed, or to print the node's value.
+func TestHelperFlush(t *testing.T) {
+	mode := os.Getenv("YDBGO_FLUSH")
+	if mode == "" {
+		t.Skip("only run as a subprocess by TestFlush()")
+	}
+	conn := NewConn()
+	n := conn.Node("^flushtest")
+	if mode == "read" {
+		val, err := n.Get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stdout.WriteString("value=" + val + "\n")
+		return
+	}
+	if err := n.Set(strings.TrimPrefix(mode, "write,")); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout.WriteString("flushed\n")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	os.Exit(0)
+}
+
+// Test that SetTrigger adds and deletes a trigger, that ListTriggers lists it, and that invalid definitions are rejected.
+func TestTrigger(t *testing.T) {
+	conn := NewConn()
+	def := `+^trigtest -commands=S -xecute="set ^trigcount=$get(^trigcount)+1" -name=ydbgoTest`
+	if err := conn.SetTrigger(def); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.SetTrigger("-ydbgoTest") })
+	triggers, err := conn.ListTriggers("^trigtest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(triggers) != 1 || !strings.HasPrefix(triggers[0], "+^trigtest ") || !strings.Contains(triggers[0], "-name=ydbgoTest") {
+		t.Errorf("got triggers %q, want %q", triggers, def)
+	}
+	if err := conn.SetTrigger("-ydbgoTest"); err != nil {
+		t.Fatal(err)
+	}
+	if triggers, err := conn.ListTriggers("^trigtest"); err != nil || len(triggers) != 0 {
+		t.Errorf("got triggers %q, %v after deleting, want none", triggers, err)
+	}
+	for _, def := range []string{"^trigtest -commands=S", "+^trigtest -commands=S", "+^trigtest -commands=S\n-xecute=\"quit\""} {
+		if err := conn.SetTrigger(def); !errors.Is(err, ErrInvalidTrigger) {
+			t.Errorf("SetTrigger(%q) returned %v, want %v", def, err, ErrInvalidTrigger)
+		}
+	}
+	if _, err := conn.ListTriggers("trigtest"); !errors.Is(err, ErrInvalidTrigger) {
+		t.Errorf("ListTriggers(%q) returned %v, want %v", "trigtest", err, ErrInvalidTrigger)
+	}
+}
@@ -0,0 +1,66 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Store Go values in nodes using a pluggable serialization format
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoCodec is returned by SetValue() and GetValue() when no codec has been registered on the connection with SetCodec().
+var ErrNoCodec = errors.New("YDB: no codec registered on the connection")
+
+// Codec serializes Go values to and from the bytes stored as node values by SetValue() and GetValue().
+// For example, a JSON codec can simply call json.Marshal() and json.Unmarshal().
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// SetCodec registers codec as the format that SetValue() and GetValue() use for nodes of conn, so that an application
+// can standardize on one format such as JSON, gob or MessagePack. A nil codec removes the registration.
+func (conn *Conn) SetCodec(codec Codec) {
+	conn.codec = codec
+}
+
+// SetValue serializes v using the codec registered on n's connection with SetCodec() and sets it as the value of n.
+// It returns an error wrapping ErrNoCodec if no codec is registered.
+func (n *Node) SetValue(v any) error {
+	if n.conn.codec == nil {
+		return fmt.Errorf("%w: setting %v", ErrNoCodec, n)
+	}
+	data, err := n.conn.codec.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("YDB: encoding value for %v: %w", n, err)
+	}
+	return n.Set(string(data))
+}
+
+// GetValue gets the value of n and deserializes it into v, which is normally a pointer, using the codec registered on
+// n's connection with SetCodec(). It returns an error wrapping ErrNoCodec if no codec is registered, or the same
+// error as Get() if n has no value.
+func (n *Node) GetValue(v any) error {
+	if n.conn.codec == nil {
+		return fmt.Errorf("%w: getting %v", ErrNoCodec, n)
+	}
+	val, err := n.Get()
+	if err != nil {
+		return err
+	}
+	if err := n.conn.codec.Unmarshal([]byte(val), v); err != nil {
+		return fmt.Errorf("YDB: decoding value of %v: %w", n, err)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// jsonCodec is a Codec that uses JSON.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+
+// Test round-tripping a struct through a registered codec.
+func TestCodec(t *testing.T) {
+	type person struct {
+		Name string
+		Age  int
+		Tags []string
+	}
+	conn := NewConn()
+	n := conn.Node("^codec", "ada")
+	ada := person{"Ada", 36, []string{"maths", "engines"}}
+	if err := n.SetValue(ada); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("got error %v, want %v", err, ErrNoCodec)
+	}
+	if err := n.GetValue(&person{}); !errors.Is(err, ErrNoCodec) {
+		t.Errorf("got error %v, want %v", err, ErrNoCodec)
+	}
+
+	conn.SetCodec(jsonCodec{})
+	if err := n.SetValue(ada); err != nil {
+		t.Fatal(err)
+	}
+	if val := n.MustGet(); val != `{"Name":"Ada","Age":36,"Tags":["maths","engines"]}` {
+		t.Errorf("got stored value %s, want JSON", val)
+	}
+	var got person
+	if err := n.GetValue(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, ada) {
+		t.Errorf("got %+v, want %+v", got, ada)
+	}
+	n.Set("not json")
+	if err := n.GetValue(&got); err == nil {
+		t.Errorf("got no error decoding an invalid value")
+	}
+}
@@ -0,0 +1,380 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Store and retrieve Go collections as database subtrees.
+
+package yottadb
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SetSlice stores each element of vals as a child of n at consecutive integer subscripts 1..len(vals).
+// It first deletes the whole subtree at n -- including any value stored at n itself -- so that the result holds
+// exactly the elements of vals. The delete and the stores are done within a single transaction.
+func (n *Node) SetSlice(vals []string) error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		if err := n.DeleteTree(); err != nil {
+			return err
+		}
+		for i, val := range vals {
+			if err := n.Child(strconv.Itoa(i + 1)).Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetWithIndexes sets the value of n to val and, within the same transaction, sets each node in indexes to its value,
+// so that secondary indexes stay consistent with the primary node. For example, to index a record by name:
+//
+//	record.SetWithIndexes(data, map[*Node]string{conn.Node("^idx", "byName", name): id})
+//
+// If any update fails, none of them are made. The index nodes may belong to any Conn; they are updated using n's Conn.
+func (n *Node) SetWithIndexes(val string, indexes map[*Node]string) error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		if err := n.Set(val); err != nil {
+			return err
+		}
+		for index, indexVal := range indexes {
+			if err := index.on(conn).Set(indexVal); err != nil {
+				return fmt.Errorf("%w: setting index %v", err, index)
+			}
+		}
+		return nil
+	})
+}
+
+// CopyToFunc copies the value of n and of each of its descendants to dstRoot, within a single transaction.
+// A node whose subscripts relative to n are subs is copied to dstRoot.Child(transform(subs)...), so that transform can
+// remap subscripts for migration or ETL, for example to anonymise or re-key them; n itself has empty subs.
+// If transform is nil, the subtree is copied with the same relative subscripts. Nodes are read and written one at a time
+// in collation order rather than gathered first. Existing nodes under dstRoot are overwritten but not deleted.
+// dstRoot must not lie within n's subtree, and transform should not map different nodes to the same destination.
+func (n *Node) CopyToFunc(dstRoot *Node, transform func(subs []string) []string) error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		src, dst := n.on(conn), dstRoot.on(conn)
+		depth := len(src.subscripts())
+		for node := range src.Tree() {
+			val, err := node.get(nil)
+			if err != nil {
+				return err
+			}
+			subs := node.subscripts()[depth:]
+			if transform != nil {
+				subs = transform(subs)
+			}
+			if err := dst.Child(subs...).Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SubtreeSize returns the number of nodes with a value in the subtree at n, including n itself, and the total length
+// in bytes of their values, to help estimate how much space the subtree uses. Values are measured with ValueLen()
+// rather than read into Go. The counts are taken as one consistent snapshot. Subscripts and the database's own
+// storage overheads are not included.
+func (n *Node) SubtreeSize() (nodes int, bytes int64, err error) {
+	err = n.conn.Snapshot(func(conn *Conn) error {
+		nodes, bytes = 0, 0
+		for node := range n.Tree() {
+			size, err := node.ValueLen()
+			if err != nil {
+				return err
+			}
+			nodes++
+			bytes += int64(size)
+		}
+		return nil
+	})
+	return nodes, bytes, err
+}
+
+// GetSlice returns the values stored at consecutive integer subscripts 1, 2, 3, ... of n, stopping at the first
+// subscript that has no value. It is the counterpart of SetSlice() and reads all elements as one consistent snapshot.
+func (n *Node) GetSlice() ([]string, error) {
+	var vals []string
+	err := n.conn.Snapshot(func(conn *Conn) error {
+		vals = nil
+		for i := 1; ; i++ {
+			val, err := n.Child(strconv.Itoa(i)).get(nil)
+			if isUndef(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			vals = append(vals, val)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// SetLines sets the value of n to lines, each followed by a newline, or by delim[0] if supplied, so that newline-delimited
+// text such as a log can be stored as a list of lines. Since every line is terminated, an empty line at the end is
+// distinct from none: []string{"a", ""} is stored as "a\n\n". No lines are stored as "". It is the counterpart of
+// GetLines(). Lines should not contain the delimiter, which would split them into more lines when read back.
+func (n *Node) SetLines(lines []string, delim ...string) error {
+	d := lineDelimiter(delim)
+	var bld strings.Builder
+	for _, line := range lines {
+		bld.WriteString(line)
+		bld.WriteString(d)
+	}
+	return n.Set(bld.String())
+}
+
+// GetLines returns the value of n split into lines at each newline, or at each delim[0] if supplied. A delimiter at the
+// end of the value terminates the last line rather than starting an empty one, so "a\nb\n" and "a\nb" both
+// give []string{"a", "b"}, and "" gives no lines. It is the counterpart of SetLines(). If n has no value, GetLines
+// returns the same error as Get().
+func (n *Node) GetLines(delim ...string) ([]string, error) {
+	val, err := n.Get()
+	if err != nil {
+		return nil, err
+	}
+	d := lineDelimiter(delim)
+	if val == "" {
+		return []string{}, nil
+	}
+	return strings.Split(strings.TrimSuffix(val, d), d), nil
+}
+
+// lineDelimiter returns delim[0] if supplied, or else a newline.
+func lineDelimiter(delim []string) string {
+	if len(delim) > 0 {
+		return delim[0]
+	}
+	return "\n"
+}
+
+// SetMap stores each entry of m as a child of n, with the map key as its subscript and the map value as its value.
+// If clear is true, SetMap first deletes the whole subtree at n -- including any value stored at n itself -- so that
+// the result holds exactly the entries of m. If clear is false, the entries of m are merged into any existing children,
+// overwriting the values of children with the same subscripts. The whole operation is done within a single transaction.
+func (n *Node) SetMap(m map[string]string, clear bool) error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		if clear {
+			if err := n.DeleteTree(); err != nil {
+				return err
+			}
+		}
+		for key, val := range m {
+			if err := n.Child(key).Set(val); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetMap returns the values of all immediate children of n in a map keyed by subscript.
+// Children that have no value of their own (only a subtree) are omitted.
+// It is the counterpart of SetMap() and reads all children as one consistent snapshot.
+func (n *Node) GetMap() (map[string]string, error) {
+	m := make(map[string]string)
+	err := n.conn.Snapshot(func(conn *Conn) error {
+		clear(m)
+		child := n.Child("")
+		for {
+			sub, err := child.SubscriptNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			child = n.Child(sub)
+			val, err := child.get(nil)
+			if isUndef(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			m[sub] = val
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ChildPairs returns the subscripts and values of all immediate children of n, in collation order.
+// Children that have no value of their own (only a subtree) are omitted, as is the metadata child MetaSubscript.
+// It is the counterpart of ChildrenWithSub() that returns a slice, and reads all children as one consistent snapshot.
+func (n *Node) ChildPairs() ([]struct{ Sub, Val string }, error) {
+	var pairs []struct{ Sub, Val string }
+	err := n.conn.Snapshot(func(conn *Conn) error {
+		pairs = pairs[:0]
+		for sub, child := range n.childrenWithSub(true) {
+			val, err := child.get(nil)
+			if isUndef(err) {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			pairs = append(pairs, struct{ Sub, Val string }{sub, val})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
+
+// DeleteChildren deletes all immediate children of n and their subtrees, while preserving any value of n itself.
+// This empties a collection stored at n but keeps n, unlike DeleteTree() which also deletes the value of n.
+// The metadata child MetaSubscript is also preserved. The children are deleted within a single transaction.
+func (n *Node) DeleteChildren() error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		for child := range n.ChildrenSkipMeta() {
+			if err := child.DeleteTree(); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteChildrenWhere deletes each immediate child of n, with its subtree, for which pred returns true when passed the
+// child's subscript and value, and returns the number of children deleted. A child with no value of its own is passed
+// the value "". The metadata child MetaSubscript is never passed to pred or deleted.
+// The children are all read before any is deleted, and the whole operation is done within a single transaction, so
+// pred may be called again for the same child if the transaction restarts.
+func (n *Node) DeleteChildrenWhere(pred func(sub, val string) bool) (int, error) {
+	var count int
+	err := n.conn.Transaction(func(conn *Conn) error {
+		var matched []string
+		for sub, child := range n.childrenWithSub(true) {
+			val, err := child.get(nil)
+			if err != nil && !isUndef(err) {
+				return err
+			}
+			if pred(sub, val) {
+				matched = append(matched, sub)
+			}
+		}
+		for _, sub := range matched {
+			if err := n.Child(sub).DeleteTree(); err != nil {
+				return err
+			}
+		}
+		count = len(matched)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ExportCSV writes the subtree at n to w as CSV, for a global shaped like ^data(id,field)=value. Each immediate child of
+// n except the metadata child MetaSubscript is a row, and each of fields is a column holding the value of n(id,field).
+// The first row is a header of "id" followed by fields, and each later row starts with its id. A field that a row lacks
+// is written as an empty cell. Values containing commas, quotes or newlines are quoted as CSV requires. Rows are
+// written as they are read rather than in one transaction, so a subtree that is updated meanwhile may be exported
+// partly before and partly after.
+func (n *Node) ExportCSV(w io.Writer, fields []string) error {
+	out := csv.NewWriter(w)
+	if err := out.Write(append([]string{"id"}, fields...)); err != nil {
+		return err
+	}
+	record := make([]string, len(fields)+1)
+	for id, row := range n.childrenWithSub(true) {
+		record[0] = id
+		for i, field := range fields {
+			val, err := row.Child(field).get([]string{""})
+			if err != nil {
+				return err
+			}
+			record[i+1] = val
+		}
+		if err := out.Write(record); err != nil {
+			return err
+		}
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// csvBatchRows is the number of CSV rows that ImportCSV() stores in each transaction.
+const csvBatchRows = 100
+
+// ImportCSV reads CSV from r and stores each row in the subtree at n, as n(id,column)=cell, where id is the row's
+// value in the column named idColumn. It is the counterpart of ExportCSV(). The first row must be a header naming the
+// columns, including idColumn. Empty cells are not stored, so they stay missing as ExportCSV() writes missing fields.
+// A row with an empty id is an error. Rows are stored in transactions of csvBatchRows rows each, so if an error
+// occurs, the transactions before it remain committed. Existing nodes in the subtree are overwritten but not deleted.
+func (n *Node) ImportCSV(r io.Reader, idColumn string) error {
+	in := csv.NewReader(r)
+	header, err := in.Read()
+	if err != nil {
+		return fmt.Errorf("YDB: reading CSV header: %w", err)
+	}
+	idIndex := slices.Index(header, idColumn)
+	if idIndex < 0 {
+		return fmt.Errorf("YDB: CSV header %q has no id column %q", header, idColumn)
+	}
+	store := func(rows [][]string) error {
+		return n.conn.Transaction(func(conn *Conn) error {
+			for _, row := range rows {
+				id := row[idIndex]
+				if id == "" {
+					return fmt.Errorf("YDB: CSV row %q has an empty id", row)
+				}
+				for i, cell := range row {
+					if i == idIndex || cell == "" {
+						continue
+					}
+					if err := n.Child(id, header[i]).Set(cell); err != nil {
+						return err
+					}
+				}
+			}
+			return nil
+		})
+	}
+	var rows [][]string
+	for {
+		row, err := in.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		rows = append(rows, row)
+		if len(rows) == csvBatchRows {
+			if err := store(rows); err != nil {
+				return err
+			}
+			rows = nil
+		}
+	}
+	return store(rows)
+}
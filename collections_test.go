@@ -0,0 +1,415 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test round-tripping Go slices through SetSlice and GetSlice.
+func TestSlice(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^slice")
+	many := make([]string, 100)
+	for i := range many {
+		many[i] = fmt.Sprintf("value%d", i)
+	}
+	for _, vals := range [][]string{{}, {"one"}, many, {"", "after empty"}} {
+		t.Run(fmt.Sprintf("Len%d", len(vals)), func(t *testing.T) {
+			if err := n.SetSlice(vals); err != nil {
+				t.Fatal(err)
+			}
+			got, err := n.GetSlice()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, vals) {
+				t.Errorf("got %v, want %v", got, vals)
+			}
+		})
+	}
+	t.Run("Clears", func(t *testing.T) {
+		n.SetSlice([]string{"a", "b", "c"})
+		n.Child("extra").Set("x")
+		n.SetSlice([]string{"z"})
+		got, _ := n.GetSlice()
+		if !slices.Equal(got, []string{"z"}) {
+			t.Errorf("got %v, want %v", got, []string{"z"})
+		}
+		if _, err := n.Child("extra").Get(); err == nil {
+			t.Errorf("got no error getting cleared node %v", n.Child("extra"))
+		}
+	})
+}
+
+// Test round-tripping Go maps through SetMap and GetMap.
+func TestMap(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^map")
+	t.Run("RoundTrip", func(t *testing.T) {
+		for _, m := range []map[string]string{{}, {"a": "1"}, {"a": "1", "b": "", "3": "three", "x y": "z"}} {
+			if err := n.SetMap(m, true); err != nil {
+				t.Fatal(err)
+			}
+			got, err := n.GetMap()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !maps.Equal(got, m) {
+				t.Errorf("got %v, want %v", got, m)
+			}
+		}
+	})
+	t.Run("Merge", func(t *testing.T) {
+		n.SetMap(map[string]string{"a": "1", "b": "2"}, true)
+		n.SetMap(map[string]string{"b": "two", "c": "3"}, false)
+		got, _ := n.GetMap()
+		want := map[string]string{"a": "1", "b": "two", "c": "3"}
+		if !maps.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+	t.Run("Clear", func(t *testing.T) {
+		n.SetMap(map[string]string{"a": "1", "b": "2"}, true)
+		n.SetMap(map[string]string{"c": "3"}, true)
+		got, _ := n.GetMap()
+		want := map[string]string{"c": "3"}
+		if !maps.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+	t.Run("SkipsNoValue", func(t *testing.T) {
+		n.SetMap(map[string]string{"a": "1"}, true)
+		n.Child("b", "deeper").Set("2")
+		got, _ := n.GetMap()
+		want := map[string]string{"a": "1"}
+		if !maps.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	})
+}
+
+// Test deleting the children of a node while keeping its value.
+func TestDeleteChildren(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^delchildren")
+	setTree(t, n, map[string]string{"a": "1", "a,x": "2", "b,y": "3", "5": "4"})
+	n.Set("container")
+	n.SetMeta("owner", "me")
+	conn.Node("^delchildrenZ").Set("sibling")
+	if err := n.DeleteChildren(); err != nil {
+		t.Fatal(err)
+	}
+	if val := n.MustGet(); val != "container" {
+		t.Errorf("got %s, want %s", val, "container")
+	}
+	if val, err := n.GetMeta("owner"); err != nil || val != "me" {
+		t.Errorf("got metadata %s, error %v; want %s", val, err, "me")
+	}
+	if children := slices.Collect(n.ChildrenSkipMeta()); len(children) != 0 {
+		t.Errorf("got %d children, want none", len(children))
+	}
+	if val := conn.Node("^delchildrenZ").MustGet(); val != "sibling" {
+		t.Errorf("got %s, want %s", val, "sibling")
+	}
+	// Deleting the children of a node without children succeeds and does nothing.
+	if err := n.DeleteChildren(); err != nil {
+		t.Error(err)
+	}
+	// A conflict while scanning the children restarts the deletion
+	setTree(t, n, map[string]string{"a": "1", "b,y": "3"})
+	conflictDuring(t, n.Child("a"), "1", n.DeleteChildren)
+	if children := slices.Collect(n.ChildrenSkipMeta()); len(children) != 0 {
+		t.Errorf("got %d children after a conflict, want none", len(children))
+	}
+}
+
+// Test that ChildPairs returns the children with values in collation order.
+func TestChildPairs(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^childpairs")
+	setTree(t, n, map[string]string{"b": "2", "a": "1", "10": "ten", "2": "two", "branch,x": "only a subtree", "c": "", "c,y": "3"})
+	n.SetMeta("owner", "me")
+	pairs, err := n.ChildPairs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ Sub, Val string }{{"2", "two"}, {"10", "ten"}, {"a", "1"}, {"b", "2"}, {"c", ""}}
+	if !slices.Equal(pairs, want) {
+		t.Errorf("got %q, want %q", pairs, want)
+	}
+	// A conflict during the scan restarts it rather than returning partial pairs
+	conflictDuring(t, n.Child("b"), "2", func() error {
+		pairs, err = n.ChildPairs()
+		return err
+	})
+	if !slices.Equal(pairs, want) {
+		t.Errorf("got %q after a conflict, want %q", pairs, want)
+	}
+	n.DeleteTree()
+	if pairs, err := n.ChildPairs(); err != nil || len(pairs) != 0 {
+		t.Errorf("got %q, error %v; want no pairs", pairs, err)
+	}
+}
+
+// Test that DeleteChildrenWhere deletes exactly the children that match, with their subtrees.
+func TestDeleteChildrenWhere(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^delwhere")
+	setTree(t, n, map[string]string{"1": "a", "2": "b", "3": "c", "3,sub": "x", "4": "d", "5,sub": "y", "10": "e"})
+	n.SetMeta("owner", "me")
+	odd := func(sub, val string) bool {
+		i, err := strconv.Atoi(sub)
+		return err == nil && i%2 == 1
+	}
+	count, err := n.DeleteChildrenWhere(odd)
+	if err != nil || count != 3 {
+		t.Fatalf("got count %d, error %v; want 3, nil", count, err)
+	}
+	if got, want := slices.Collect(n.ChildSubscripts()), []string{"2", "4", "10", MetaSubscript}; !slices.Equal(got, want) {
+		t.Errorf("got survivors %q, want %q", got, want)
+	}
+	count, err = n.DeleteChildrenWhere(func(sub, val string) bool { return val == "d" })
+	if err != nil || count != 1 {
+		t.Errorf("got count %d, error %v; want 1, nil", count, err)
+	}
+	if count, err = n.DeleteChildrenWhere(odd); err != nil || count != 0 {
+		t.Errorf("got count %d, error %v; want 0, nil", count, err)
+	}
+
+	// A conflict during the scan restarts it, and only the last attempt is counted
+	n.Child("11").Set("f")
+	n.Child("12").Set("g")
+	conflictDuring(t, n.Child("2"), "b", func() error {
+		count, err = n.DeleteChildrenWhere(odd)
+		return err
+	})
+	if got, want := slices.Collect(n.ChildSubscripts()), []string{"2", "10", "12", MetaSubscript}; count != 1 || !slices.Equal(got, want) {
+		t.Errorf("got count %d and survivors %q after a conflict, want 1 and %q", count, got, want)
+	}
+}
+
+// Test that SetWithIndexes sets a record and its index entries together or not at all.
+func TestSetWithIndexes(t *testing.T) {
+	conn := NewConn()
+	conn.Node("^idxrec").DeleteTree()
+	conn.Node("^idx").DeleteTree()
+	record := conn.Node("^idxrec", "42")
+	byName := NewConn().Node("^idx", "byName", "Ada") // index nodes may belong to another Conn
+	byCity := conn.Node("^idx", "byCity", "London", "42")
+	if err := record.SetWithIndexes("Ada,London", map[*Node]string{byName: "42", byCity: ""}); err != nil {
+		t.Fatal(err)
+	}
+	for _, check := range []struct {
+		n    *Node
+		want string
+	}{{record, "Ada,London"}, {byName, "42"}, {byCity, ""}} {
+		if val, err := check.n.Get(); err != nil || val != check.want {
+			t.Errorf("%v: got %q, %v, want %q", check.n, val, err, check.want)
+		}
+	}
+
+	other := conn.Node("^idxrec", "43")
+	err := other.SetWithIndexes("Bob", map[*Node]string{conn.Node("^idx", "byName", "Bob"): "43", conn.Node("$ZVERSION"): "x"})
+	if err == nil {
+		t.Fatalf("got no error setting an invalid index")
+	}
+	for _, n := range []*Node{other, conn.Node("^idx", "byName", "Bob")} {
+		if data, _ := n.Data(); data != 0 {
+			t.Errorf("got %v set after a failed update, want it rolled back", n)
+		}
+	}
+}
+
+// Test copying a tree while transforming its subscripts.
+func TestCopyToFunc(t *testing.T) {
+	conn := NewConn()
+	src := conn.Node("^copysrc", "people")
+	dst := conn.Node("^copydst")
+	dst.DeleteTree()
+	setTree(t, src, map[string]string{"ada,age": "36", "ada,city": "London", "bob,age": "40"})
+	src.Set("root")
+	upper := func(subs []string) []string {
+		if len(subs) > 0 {
+			subs[0] = strings.ToUpper(subs[0])
+		}
+		return subs
+	}
+	if err := src.CopyToFunc(dst, upper); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"": "root", "ADA,age": "36", "ADA,city": "London", "BOB,age": "40"}
+	got := map[string]string{}
+	for node := range dst.Tree() {
+		got[strings.Join(node.subscripts(), ",")] = node.MustGet()
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	plain := conn.Node("^copydst", "plain")
+	if err := src.Child("ada").CopyToFunc(plain, nil); err != nil {
+		t.Fatal(err)
+	}
+	if val := plain.Child("city").MustGet(); val != "London" {
+		t.Errorf("got %s, want %s", val, "London")
+	}
+
+	// A conflict partway through the copy restarts it
+	again := conn.Node("^copydst", "again")
+	conflictDuring(t, src.Child("bob", "age"), "40", func() error { return src.CopyToFunc(again, nil) })
+	if val, _ := again.Child("bob", "age").Get(); val != "40" {
+		t.Errorf("got %s after a conflict, want %s", val, "40")
+	}
+}
+
+// Test counting the nodes and value bytes of a subtree.
+func TestSubtreeSize(t *testing.T) {
+	n := NewConn().Node("^subtreesize", "root")
+	setTree(t, n, map[string]string{"a": "12345", "a,1": "", "b,c,d": strings.Repeat("x", 1000)})
+	n.Set("abc")
+	NewConn().Node("^subtreesize", "outside").Set("not counted")
+	nodes, bytes, err := n.SubtreeSize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nodes != 4 || bytes != 1008 {
+		t.Errorf("got %d nodes of %d bytes, want %d nodes of %d bytes", nodes, bytes, 4, 1008)
+	}
+	if nodes, bytes, _ := n.Child("absent").SubtreeSize(); nodes != 0 || bytes != 0 {
+		t.Errorf("got %d nodes of %d bytes for an absent subtree, want none", nodes, bytes)
+	}
+	conflictDuring(t, n.Child("a"), "12345", func() error {
+		nodes, bytes, err = n.SubtreeSize()
+		return err
+	})
+	if nodes != 4 || bytes != 1008 {
+		t.Errorf("got %d nodes of %d bytes after a conflict, want %d nodes of %d bytes", nodes, bytes, 4, 1008)
+	}
+}
+
+// conflictDuring runs fn within a transaction, and in its first attempt first makes another process set node to val,
+// which should be its current value so that the data is unchanged. So when fn walks a subtree containing node within
+// a nested transaction, YottaDB detects the conflict and the transaction restarts. conflictDuring fails t unless fn
+// was retried and succeeded, rather than, say, the iterator panicking on the YDB_TP_RESTART error.
+func conflictDuring(t *testing.T, node *Node, val string, fn func() error) {
+	t.Helper()
+	attempts := 0
+	err := node.conn.Transaction(func(conn *Conn) error {
+		attempts++
+		if attempts == 1 {
+			if err := setElsewhere(val, node.varname(), node.subscripts()...); err != nil {
+				return err
+			}
+		}
+		return fn()
+	})
+	if err != nil || attempts < 2 {
+		t.Errorf("got error %v after %d attempts, want success after a restart", err, attempts)
+	}
+}
+
+// Test exporting a two-level global as CSV.
+func TestExportCSV(t *testing.T) {
+	n := NewConn().Node("^csv")
+	setTree(t, n, map[string]string{"1,name": "Alice", "1,city": "Paris", "2,name": "Bob, Jr.", "2,age": "40",
+		"3,name": "Line\nbreak", "3,city": `Say "hi"`})
+	n.SetMeta("owner", "me")
+	var buf strings.Builder
+	if err := n.ExportCSV(&buf, []string{"name", "city"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,city\n1,Alice,Paris\n2,\"Bob, Jr.\",\n3,\"Line\nbreak\",\"Say \"\"hi\"\"\"\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+// Test importing CSV and exporting it back.
+func TestImportCSV(t *testing.T) {
+	n := NewConn().Node("^csvimport")
+	n.DeleteTree()
+	csv := "name,code,city\nAlice,a1,Paris\n\"Bob, Jr.\",b2,\nCarol,c3,\"Say \"\"hi\"\"\"\n"
+	if err := n.ImportCSV(strings.NewReader(csv), "code"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := n.Child("b2", "name").Get(); got != "Bob, Jr." {
+		t.Errorf("got %q, want %q", got, "Bob, Jr.")
+	}
+	if data, _ := n.Child("b2", "city").Data(); data != 0 {
+		t.Errorf("got an empty cell stored")
+	}
+	var buf strings.Builder
+	if err := n.ExportCSV(&buf, []string{"name", "city"}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,name,city\na1,Alice,Paris\nb2,\"Bob, Jr.\",\nc3,Carol,\"Say \"\"hi\"\"\"\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	// Many rows are stored over several transactions
+	var big strings.Builder
+	big.WriteString("id,val\n")
+	for i := range 2*csvBatchRows + 1 {
+		fmt.Fprintf(&big, "%d,v%d\n", i, i)
+	}
+	if err := n.ImportCSV(strings.NewReader(big.String()), "id"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := n.Child(fmt.Sprint(2*csvBatchRows), "val").Get(); got != fmt.Sprintf("v%d", 2*csvBatchRows) {
+		t.Errorf("got last row value %q", got)
+	}
+
+	for _, bad := range []string{"", "a,b\n1,2\n", "id,b\n,2\n"} {
+		if err := n.ImportCSV(strings.NewReader(bad), "id"); err == nil {
+			t.Errorf("got no error importing %q", bad)
+		}
+	}
+}
+
+// Test storing and retrieving lines with SetLines and GetLines.
+func TestLines(t *testing.T) {
+	n := NewConn().Node("^lines")
+	for _, lines := range [][]string{{"a", "b"}, {}, {""}, {"a", ""}, {"", "", "x"}} {
+		if err := n.SetLines(lines); err != nil {
+			t.Fatal(err)
+		}
+		if got, err := n.GetLines(); err != nil || !slices.Equal(got, lines) {
+			t.Errorf("%q: got %q, %v", lines, got, err)
+		}
+	}
+	// The trailing delimiter is optional when reading values not written by SetLines
+	for val, want := range map[string][]string{"a\nb": {"a", "b"}, "a\nb\n": {"a", "b"}, "\n": {""}, "": {}} {
+		n.Set(val)
+		if got, _ := n.GetLines(); !slices.Equal(got, want) {
+			t.Errorf("%q: got %q, want %q", val, got, want)
+		}
+	}
+	n.SetLines([]string{"x", "y\nz"}, "|")
+	if val := n.MustGet(); val != "x|y\nz|" {
+		t.Errorf("got value %q, want %q", val, "x|y\nz|")
+	}
+	if got, _ := n.GetLines("|"); !slices.Equal(got, []string{"x", "y\nz"}) {
+		t.Errorf("got %q with delimiter |", got)
+	}
+	n.Delete()
+	if _, err := n.GetLines(); !isUndef(err) {
+		t.Errorf("got error %v, want GVUNDEF", err)
+	}
+}
@@ -0,0 +1,64 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Store node values compressed with gzip
+
+package yottadb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// compressedMarker prefixes values stored by SetCompressed() so that GetCompressed() can tell them apart from values
+// stored uncompressed. It starts with a NUL byte, which text values such as JSON never contain.
+const compressedMarker = "\x00gz"
+
+// SetCompressed sets the value of n to val compressed with gzip, which saves space when storing large, verbose text
+// such as JSON. Use GetCompressed() to retrieve the original value.
+func (n *Node) SetCompressed(val []byte) error {
+	var buf bytes.Buffer
+	buf.WriteString(compressedMarker)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(val); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return n.Set(buf.String())
+}
+
+// GetCompressed returns the value of n stored by SetCompressed(), uncompressed.
+// Values that were stored without compression, for example by Set() before a node was converted to SetCompressed(),
+// are returned unchanged. As with Get(), an error is returned if n has no value.
+func (n *Node) GetCompressed() ([]byte, error) {
+	val, err := n.Get()
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(val, compressedMarker) {
+		return []byte(val), nil
+	}
+	r, err := gzip.NewReader(strings.NewReader(val[len(compressedMarker):]))
+	if err != nil {
+		return nil, fmt.Errorf("YDB: invalid compressed value in %v: %w", n, err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("YDB: invalid compressed value in %v: %w", n, err)
+	}
+	return data, nil
+}
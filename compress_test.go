@@ -0,0 +1,67 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+)
+
+// Test that compressed values round-trip and that uncompressed values are still read.
+func TestCompressed(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^compressed")
+	random := make([]byte, 10000)
+	rnd := rand.New(rand.NewChaCha8([32]byte{}))
+	for i := range random {
+		random[i] = byte(rnd.Uint32())
+	}
+	for _, test := range []struct {
+		name string
+		val  []byte
+	}{
+		{"empty", []byte{}},
+		{"compressible", bytes.Repeat([]byte(`{"key": "value"}, `), 10000)},
+		{"incompressible", random},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			if err := n.SetCompressed(test.val); err != nil {
+				t.Fatal(err)
+			}
+			got, err := n.GetCompressed()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, test.val) {
+				t.Errorf("got %d bytes, want %d bytes unchanged", len(got), len(test.val))
+			}
+		})
+	}
+	if stored, _ := n.ValueLen(); stored > len(random)+100 {
+		t.Errorf("incompressible value stored in %d bytes, want little overhead", stored)
+	}
+
+	n.SetCompressed(bytes.Repeat([]byte("x"), 100000))
+	if stored, _ := n.ValueLen(); stored > 1000 {
+		t.Errorf("compressible value stored in %d bytes, want it compressed", stored)
+	}
+	n.Set("legacy")
+	if got, err := n.GetCompressed(); err != nil || string(got) != "legacy" {
+		t.Errorf("got %q, %v, want %q", got, err, "legacy")
+	}
+	n.Set(compressedMarker + "garbage")
+	if _, err := n.GetCompressed(); err == nil {
+		t.Errorf("got no error for invalid compressed value")
+	}
+}
@@ -0,0 +1,159 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Compare database subtrees
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+)
+
+// ErrDiffMismatch is returned by Apply() when a DiffEntry does not match the current content of the tree.
+var ErrDiffMismatch = errors.New("YDB: diff entry does not match the database")
+
+// DiffKind specifies the kind of change that a DiffEntry describes.
+type DiffKind int
+
+const (
+	DiffAdded   DiffKind = iota // the node has a value only in the second tree
+	DiffRemoved                 // the node has a value only in the first tree
+	DiffChanged                 // the node has different values in the two trees
+)
+
+// String returns the name of a DiffKind.
+func (k DiffKind) String() string {
+	switch k {
+	case DiffAdded:
+		return "added"
+	case DiffRemoved:
+		return "removed"
+	case DiffChanged:
+		return "changed"
+	}
+	return "unknown"
+}
+
+// DiffEntry describes one difference between two subtrees, as returned by Node.Diff().
+type DiffEntry struct {
+	Subscripts []string // path of the node relative to the root of each subtree
+	Kind       DiffKind
+	Old        string // value in the first tree (empty for DiffAdded)
+	New        string // value in the second tree (empty for DiffRemoved)
+}
+
+// Diff compares the subtree at n with the subtree at other and returns the differences between the nodes that have
+// values, in collation order. Each DiffEntry identifies a node by its subscripts relative to n or other, so the two
+// subtrees may have different varnames or lie at different depths.
+// Both trees are walked just once, together, in collation order using Tree().
+func (n *Node) Diff(other *Node) ([]DiffEntry, error) {
+	nextA, stopA := iter.Pull(n.Tree())
+	defer stopA()
+	nextB, stopB := iter.Pull(other.Tree())
+	defer stopB()
+	rootA, rootB := n.n.len-1, other.n.len-1
+
+	var diffs []DiffEntry
+	a, okA := nextA()
+	b, okB := nextB()
+	for okA || okB {
+		var subsA, subsB []string
+		if okA {
+			subsA = a.subscripts()[rootA:]
+		}
+		if okB {
+			subsB = b.subscripts()[rootB:]
+		}
+		cmp := 0
+		switch {
+		case !okA:
+			cmp = 1
+		case !okB:
+			cmp = -1
+		default:
+			cmp = collateSubscripts(subsA, subsB)
+		}
+		switch cmp {
+		case -1:
+			val, err := a.Get()
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, DiffEntry{Subscripts: subsA, Kind: DiffRemoved, Old: val})
+			a, okA = nextA()
+		case 1:
+			val, err := b.Get()
+			if err != nil {
+				return nil, err
+			}
+			diffs = append(diffs, DiffEntry{Subscripts: subsB, Kind: DiffAdded, New: val})
+			b, okB = nextB()
+		default:
+			valA, err := a.Get()
+			if err != nil {
+				return nil, err
+			}
+			valB, err := b.Get()
+			if err != nil {
+				return nil, err
+			}
+			if valA != valB {
+				diffs = append(diffs, DiffEntry{Subscripts: subsA, Kind: DiffChanged, Old: valA, New: valB})
+			}
+			a, okA = nextA()
+			b, okB = nextB()
+		}
+	}
+	return diffs, nil
+}
+
+// Apply applies a list of differences, as returned by Diff(), to the subtree at n: DiffAdded and DiffChanged entries
+// set a node to the New value and DiffRemoved entries delete the node's value (leaving any subtree below it).
+// Each entry is first checked against the current database: an added node must not already have a value, and a
+// removed or changed node must still hold the Old value. All entries are applied atomically in a single transaction,
+// so if any entry does not match, nothing is changed and an error wrapping ErrDiffMismatch is returned.
+func (n *Node) Apply(entries []DiffEntry) error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		for _, entry := range entries {
+			node := n.Child(entry.Subscripts...)
+			val, err := node.get(nil)
+			exists := !isUndef(err)
+			if exists && err != nil {
+				return err
+			}
+			switch entry.Kind {
+			case DiffAdded:
+				if exists {
+					return fmt.Errorf("%w: %v already has a value", ErrDiffMismatch, node)
+				}
+				err = node.Set(entry.New)
+			case DiffRemoved, DiffChanged:
+				if !exists || val != entry.Old {
+					return fmt.Errorf("%w: %v does not have the expected value", ErrDiffMismatch, node)
+				}
+				if entry.Kind == DiffRemoved {
+					err = node.Delete()
+				} else {
+					err = node.Set(entry.New)
+				}
+			default:
+				return fmt.Errorf("%w: invalid DiffKind %d", ErrDiffMismatch, entry.Kind)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
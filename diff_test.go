@@ -0,0 +1,98 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setTree replaces the subtree at n with the given values, keyed by comma-separated subscripts relative to n.
+func setTree(t *testing.T, n *Node, values map[string]string) {
+	if err := n.DeleteTree(); err != nil {
+		t.Fatal(err)
+	}
+	for path, val := range values {
+		if err := n.Child(strings.Split(path, ",")...).Set(val); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// Test diffing two subtrees with a mix of additions, deletions and changes.
+func TestDiff(t *testing.T) {
+	conn := NewConn()
+	a, b := conn.Node("^diff", "a"), conn.Node("diffb")
+	setTree(t, a, map[string]string{"x": "1", "y": "2", "z,1": "3", "z,10": "same"})
+	setTree(t, b, map[string]string{"x": "1", "y": "22", "z,2": "4", "w": "5", "z,10": "same"})
+
+	got, err := a.Diff(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []DiffEntry{
+		{Subscripts: []string{"w"}, Kind: DiffAdded, New: "5"},
+		{Subscripts: []string{"y"}, Kind: DiffChanged, Old: "2", New: "22"},
+		{Subscripts: []string{"z", "1"}, Kind: DiffRemoved, Old: "3"},
+		{Subscripts: []string{"z", "2"}, Kind: DiffAdded, New: "4"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	got, err = a.Diff(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("got %v diffing a tree against itself, want no differences", got)
+	}
+}
+
+// Test that applying the diff of A against B to a copy of A makes it equal to B.
+func TestApply(t *testing.T) {
+	conn := NewConn()
+	a, b, c := conn.Node("^apply", "a"), conn.Node("^apply", "b"), conn.Node("^apply", "c")
+	treeA := map[string]string{"x": "1", "y": "2", "z,1": "3", "z,1,deep": "4"}
+	setTree(t, a, treeA)
+	setTree(t, b, map[string]string{"x": "1", "y": "22", "z,2": "5", "z,1,deep": "4", "w": "6"})
+	setTree(t, c, treeA)
+
+	patch, err := a.Diff(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Apply(patch); err != nil {
+		t.Fatal(err)
+	}
+	diffs, err := c.Diff(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("got differences %v after applying patch, want none", diffs)
+	}
+
+	// A patch that does not match the tree must fail as a whole without changing anything
+	setTree(t, c, treeA)
+	c.Child("y").Set("unexpected")
+	err = c.Apply(patch)
+	if !errors.Is(err, ErrDiffMismatch) {
+		t.Errorf("got error %v, want %v", err, ErrDiffMismatch)
+	}
+	if val, _ := c.Child("w").Get("absent"); val != "absent" {
+		t.Errorf("got %s, want partial patch to be rolled back", val)
+	}
+}
@@ -29,6 +29,12 @@ func (err *YDBError) Code() int {
 	return err.code
 }
 
+// Is lets errors.Is() match a YDBError against a sentinel YDBError that has the same error code.
+func (err *YDBError) Is(target error) bool {
+	t, ok := target.(*YDBError)
+	return ok && t.code == err.code
+}
+
 func Error(code int, message string) error {
 	return &YDBError{code, message}
 }
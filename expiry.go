@@ -0,0 +1,232 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Emulate values that expire after a time-to-live, since YottaDB has no native expiry.
+
+package yottadb
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// expiryKey is the metadata key (see SetMeta()) that holds the expiry time of a node's value, in Unix nanoseconds.
+const expiryKey = "expiry"
+
+// accessedKey is the metadata key (see SetMeta()) where Touch() records when a node was last accessed, in Unix nanoseconds.
+const accessedKey = "accessed"
+
+// touchSubs are the subscripts MetaSubscript and accessedKey in C memory, for Touch() to append to a node's subscripts.
+var touchSubs = [2]C.ydb_buffer_t{
+	{buf_addr: C.CString(MetaSubscript), len_used: C.uint(len(MetaSubscript)), len_alloc: C.uint(len(MetaSubscript))},
+	{buf_addr: C.CString(accessedKey), len_used: C.uint(len(accessedKey)), len_alloc: C.uint(len(accessedKey))},
+}
+
+// Touch records the current time as metadata key "accessed" of n, that is, in n(MetaSubscript,"accessed"), in Unix
+// nanoseconds. Read it with GetMeta("accessed"). Touch neither reads nor changes the value of n, and it calls
+// ydb_set_st() directly without creating a Node for the metadata, so that caches built on YottaDB can cheaply
+// track which entries were used least recently. Like Set(), the update of the metadata node is reported to
+// StartRecording() and OnCommit(); only then is a Node created for it.
+func (n *Node) Touch() error {
+	if n.conn.probing {
+		return n.conn.probeWrite()
+	}
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	// The subscripts of n followed by touchSubs; a Go array may be passed to C since it holds only C pointers
+	nsubs := int(c_n.len - 1)
+	subs := make([]C.ydb_buffer_t, nsubs+len(touchSubs))
+	copy(subs, unsafe.Slice((*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), nsubs))
+	copy(subs[nsubs:], touchSubs[:])
+
+	var stamp [20]byte
+	val := strconv.AppendInt(stamp[:0], time.Now().UnixNano(), 10)
+	C.memcpy(unsafe.Pointer(conn.value.buf_addr), unsafe.Pointer(&val[0]), C.size_t(len(val)))
+	conn.value.len_used = C.uint(len(val))
+	ret := C.ydb_set_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], C.int(len(subs)), &subs[0], &conn.value)
+	if ret == C.YDB_OK && (n.conn.recorder != nil || n.conn.tx != nil && (n.conn.onCommit != nil || n.conn.verifyReads)) {
+		meta := n.Child(MetaSubscript, accessedKey)
+		n.conn.recordChange(meta)
+		n.conn.recordWrite(meta, string(val))
+		n.conn.recordUpdate(recordSet, meta, string(val))
+	}
+	return n.conn.Error(ret)
+}
+
+// SetWithExpiry sets the value of n to val and records that the value expires after ttl, like a cache entry.
+// The expiry time is stored as metadata of n (see SetMeta()). Expired values are not deleted automatically: read them
+// with GetUnexpired() to treat them as absent, and delete them with SweepExpired() or StartExpirySweeper().
+// If ttl <= 0 the value never expires. A later Set() of n keeps any expiry time; use SetWithExpiry() to change it.
+func (n *Node) SetWithExpiry(val string, ttl time.Duration) error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		if err := n.Set(val); err != nil {
+			return err
+		}
+		expiry := n.Child(MetaSubscript, expiryKey)
+		if ttl <= 0 {
+			return expiry.Delete()
+		}
+		return expiry.Set(strconv.FormatInt(time.Now().Add(ttl).UnixNano(), 10))
+	})
+}
+
+// GetUnexpired returns the value of n like Get(), except that a value whose expiry time has passed is treated as
+// absent: GetUnexpired then returns deflt[0] if supplied, or else the same YottaDB error as Get() returns for a node
+// with no value (YDB_ERR_GVUNDEF or YDB_ERR_LVUNDEF).
+func (n *Node) GetUnexpired(deflt ...string) (string, error) {
+	var val string
+	err := n.conn.Snapshot(func(conn *Conn) error {
+		expired, err := n.expired(time.Now())
+		if err != nil {
+			return err
+		}
+		if expired {
+			code := C.YDB_ERR_LVUNDEF
+			if strings.HasPrefix(n.varname(), "^") {
+				code = C.YDB_ERR_GVUNDEF
+			}
+			return Error(int(code), fmt.Sprintf("YDB: value of %v has expired", n))
+		}
+		val, err = n.Get()
+		return err
+	})
+	if isUndef(err) {
+		if len(deflt) > 0 {
+			return deflt[0], nil
+		}
+		if n.conn.undefMode == UndefEmptyString {
+			return "", nil
+		}
+	}
+	return val, err
+}
+
+// GetLive returns the value of n and true if n has a value that has not expired, or else "" and false, without an
+// error, so that an expired value that has not yet been swept reads like a missing cache entry. A value without an
+// expiry time is always live. Errors other than a missing value are returned.
+func (n *Node) GetLive() (string, bool, error) {
+	var val string
+	var found bool
+	err := n.conn.Snapshot(func(conn *Conn) error {
+		val, found = "", false
+		expired, err := n.expired(time.Now())
+		if err != nil || expired {
+			return err
+		}
+		val, err = n.get(nil)
+		if isUndef(err) {
+			return nil
+		}
+		found = err == nil
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return val, found, nil
+}
+
+// expired reports whether n has an expiry time that is not after now.
+func (n *Node) expired(now time.Time) (bool, error) {
+	s, err := n.Child(MetaSubscript, expiryKey).get(nil)
+	if isUndef(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	expiry, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return false, fmt.Errorf("YDB: invalid expiry time %q of %v", s, n)
+	}
+	return expiry <= now.UnixNano(), nil
+}
+
+// SweepExpired deletes the values of n and its descendants that have expired, along with their expiry times, and
+// returns the number of values deleted. Only the expired values themselves are deleted, not the subtrees below them.
+func (n *Node) SweepExpired() (int, error) {
+	// Collect the expired nodes first so as not to modify the tree while traversing it
+	now := time.Now()
+	var expired []*Node
+	for node := range n.Tree() {
+		subs := node.subscripts()
+		if len(subs) < 2 || subs[len(subs)-2] != MetaSubscript || subs[len(subs)-1] != expiryKey {
+			continue
+		}
+		parent := n.conn.Node(node.varname(), subs[:len(subs)-2]...)
+		isExpired, err := parent.expired(now)
+		if err != nil {
+			return 0, err
+		}
+		if isExpired {
+			expired = append(expired, parent)
+		}
+	}
+	count := 0
+	for _, node := range expired {
+		deleted := false
+		err := n.conn.Transaction(func(conn *Conn) error {
+			// Check again in case the value was set with a new expiry time since it was found
+			isExpired, err := node.expired(now)
+			if err != nil || !isExpired {
+				deleted = false
+				return err
+			}
+			if err := node.Delete(); err != nil {
+				return err
+			}
+			deleted = true
+			return node.Child(MetaSubscript, expiryKey).Delete()
+		})
+		if err != nil {
+			return count, err
+		}
+		if deleted {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// StartExpirySweeper starts a goroutine that calls SweepExpired() on n every interval until ctx is cancelled.
+// Since a Conn may not be used by more than one goroutine at a time, the sweeper makes its own connection rather than
+// use that of n. If a sweep fails, the sweeper passes the error to onError, unless onError is nil, and tries again
+// after the next interval. onError is called from the sweeper's goroutine.
+func (n *Node) StartExpirySweeper(ctx context.Context, interval time.Duration, onError func(error)) {
+	root := n.on(NewConn())
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			if _, err := root.SweepExpired(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}()
+}
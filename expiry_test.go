@@ -0,0 +1,184 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that expired values read as absent and are deleted by sweeping.
+func TestExpiry(t *testing.T) {
+	conn := NewConn()
+	root := conn.Node("^expiry")
+	root.DeleteTree()
+	short, long, plain := root.Child("short"), root.Child("long"), root.Child("plain")
+	const ttl = 20 * time.Millisecond
+
+	if err := short.SetWithExpiry("s", ttl); err != nil {
+		t.Fatal(err)
+	}
+	short.Child("child").Set("kept")
+	long.SetWithExpiry("l", time.Hour)
+	plain.Set("p")
+	if val, err := short.GetUnexpired(); err != nil || val != "s" {
+		t.Errorf("got %s, %v, want %s", val, err, "s")
+	}
+	time.Sleep(2 * ttl)
+
+	t.Run("Get", func(t *testing.T) {
+		if _, err := short.GetUnexpired(); !isUndef(err) {
+			t.Errorf("got error %v, want undefined", err)
+		}
+		if val, err := short.GetUnexpired("default"); err != nil || val != "default" {
+			t.Errorf("got %s, %v, want %s", val, err, "default")
+		}
+		if val, _ := short.Get(); val != "s" {
+			t.Errorf("got %s, want Get to ignore expiry and return %s", val, "s")
+		}
+		for _, n := range []*Node{long, plain} {
+			if _, err := n.GetUnexpired(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("GetLive", func(t *testing.T) {
+		for _, test := range []struct {
+			n     *Node
+			want  string
+			found bool
+		}{{short, "", false}, {long, "l", true}, {plain, "p", true}, {root.Child("absent"), "", false}} {
+			val, found, err := test.n.GetLive()
+			if err != nil || val != test.want || found != test.found {
+				t.Errorf("%v: got %q, %v, %v; want %q, %v, nil", test.n, val, found, err, test.want, test.found)
+			}
+		}
+	})
+
+	t.Run("Sweep", func(t *testing.T) {
+		count, err := root.SweepExpired()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != 1 {
+			t.Errorf("got %d values swept, want %d", count, 1)
+		}
+		if data, _ := short.Data(); data != 10 {
+			t.Errorf("got data %d, want %d: only the expired value should be deleted", data, 10)
+		}
+		if _, err := short.GetMeta(expiryKey); !isUndef(err) {
+			t.Errorf("got error %v, want expiry time deleted", err)
+		}
+		for _, n := range []*Node{long, plain} {
+			if _, err := n.Get(); err != nil {
+				t.Error(err)
+			}
+		}
+	})
+
+	t.Run("Sweeper", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		short.SetWithExpiry("s", ttl)
+		root.StartExpirySweeper(ctx, ttl/2, nil)
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(ttl / 2) {
+			if data, _ := short.Data(); data == 10 {
+				return
+			}
+		}
+		t.Errorf("expired value of %v was not swept", short)
+	})
+
+	t.Run("SweeperError", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		bad := root.Child("bad", MetaSubscript, expiryKey)
+		bad.Set("never")
+		defer bad.Delete()
+		errs := make(chan error, 1)
+		root.StartExpirySweeper(ctx, ttl/2, func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		})
+		select {
+		case err := <-errs:
+			if !strings.Contains(err.Error(), "invalid expiry time") {
+				t.Errorf("got error %v, want invalid expiry time", err)
+			}
+		case <-time.After(5 * time.Second):
+			t.Errorf("sweep error was not reported")
+		}
+	})
+}
+
+// Test that Touch records the access time without affecting the value.
+func TestTouch(t *testing.T) {
+	n := NewConn().Node("^touch", "entry")
+	n.DeleteTree()
+	n.Set("cached")
+	before := time.Now().UnixNano()
+	if err := n.Touch(); err != nil {
+		t.Fatal(err)
+	}
+	first, err := n.GetMeta("accessed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ns, _ := strconv.ParseInt(first, 10, 64); ns < before || ns > time.Now().UnixNano() {
+		t.Errorf("got access time %s, want between %d and now", first, before)
+	}
+	time.Sleep(time.Millisecond)
+	n.Touch()
+	if second, _ := n.GetMeta("accessed"); second <= first {
+		t.Errorf("got access time %s after touching again, want later than %s", second, first)
+	}
+	if val := n.MustGet(); val != "cached" {
+		t.Errorf("got %s, want %s", val, "cached")
+	}
+	if children := slices.Collect(n.ChildSubscripts()); len(children) != 1 || children[0] != MetaSubscript {
+		t.Errorf("got children %q, want only metadata", children)
+	}
+
+	// Touch is recorded and reported to OnCommit like Set
+	conn := n.conn
+	var buf strings.Builder
+	conn.StartRecording(&buf)
+	n.Touch()
+	if err := conn.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+	stamp, _ := n.GetMeta("accessed")
+	// the stamp is quoted since its 19 digits are too many for a canonical number
+	if want := fmt.Sprintf("S\t^touch\t\"entry\"\t$C(0)_\"meta\"\t\"accessed\"\t%q\n", stamp); buf.String() != want {
+		t.Errorf("got recording %q, want %q", buf.String(), want)
+	}
+	var changed []string
+	conn.OnCommit(func(nodes []*Node) {
+		for _, node := range nodes {
+			changed = append(changed, node.String())
+		}
+	})
+	defer conn.OnCommit(nil)
+	conn.Transaction(func(conn *Conn) error { return n.Touch() })
+	if want := n.Child(MetaSubscript, "accessed").String(); !slices.Equal(changed, []string{want}) {
+		t.Errorf("got changes %q, want %q", changed, want)
+	}
+}
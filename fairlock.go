@@ -0,0 +1,112 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Implement a lock that is granted in order of arrival
+
+package yottadb
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// FairLock uses a ticket discipline kept in metadata of the node n being locked (see SetMeta()):
+//   - n(MetaSubscript,"ticket") counts the tickets issued; each waiter takes the next ticket with Increment().
+//   - n(MetaSubscript,"served") counts the tickets whose turn has finished; ticket t holds the lock when it is t-1.
+//   - n(MetaSubscript,"abandoned",t) marks ticket t as given up by a waiter whose context ended, so that it is skipped.
+
+// fairPollInterval is how often FairLock() checks whether its turn has come.
+const fairPollInterval = time.Millisecond
+
+// FairLock waits until it is this caller's turn to hold an exclusive lock on n, granting the lock to waiters in the
+// order they arrive so that none is starved under heavy contention, unlike Lock(). It returns a function that releases
+// the lock and returns any error doing so. If releasing fails, for example on a transient database error, the lock is
+// still held and all later waiters stay blocked, so the release function should be called again until it succeeds;
+// once it has succeeded, calling it again has no further effect. If ctx ends first, FairLock gives up its place in the
+// queue and returns ctx.Err().
+// The queue is kept in global counters stored under n(MetaSubscript,...), so n should be a global node for the lock to
+// work between processes. Each acquisition therefore costs several database updates, and waiters poll every
+// millisecond, so FairLock is much slower than Lock() and is only worthwhile under contention.
+// FairLock does not take a YottaDB lock on n: it only excludes other callers of FairLock(), so all participants must
+// use it. A process that exits while holding the lock (or its place in the queue) blocks all later waiters until the
+// counters are reset.
+func (n *Node) FairLock(ctx context.Context) (release func() error, err error) {
+	noop := func() error { return nil }
+	ticketStr, err := n.Child(MetaSubscript, "ticket").Increment("")
+	if err != nil {
+		return noop, err
+	}
+	ticket, _ := strconv.ParseInt(ticketStr, 10, 64)
+	served := n.Child(MetaSubscript, "served")
+	released := false
+	release = func() error {
+		if released {
+			return nil
+		}
+		if err := n.fairAdvance(); err != nil {
+			return err
+		}
+		released = true
+		return nil
+	}
+	for {
+		// Check our turn and abandon the ticket atomically so that a release cannot slip in between
+		turn := false
+		err := n.conn.Transaction(func(conn *Conn) error {
+			s, err := served.Get("0")
+			if err != nil {
+				return err
+			}
+			count, _ := strconv.ParseInt(s, 10, 64)
+			turn = count == ticket-1
+			if turn || ctx.Err() == nil {
+				return nil
+			}
+			return n.Child(MetaSubscript, "abandoned", ticketStr).Set("")
+		})
+		switch {
+		case err != nil:
+			return noop, err
+		case turn:
+			return release, nil
+		case ctx.Err() != nil:
+			return noop, ctx.Err()
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(fairPollInterval):
+		}
+	}
+}
+
+// fairAdvance ends the current turn of FairLock() on n, passing the lock to the next ticket that was not abandoned.
+func (n *Node) fairAdvance() error {
+	return n.conn.Transaction(func(conn *Conn) error {
+		served := n.Child(MetaSubscript, "served")
+		for {
+			next, err := served.Increment("")
+			if err != nil {
+				return err
+			}
+			nextTicket, _ := strconv.ParseInt(next, 10, 64)
+			abandoned := n.Child(MetaSubscript, "abandoned", strconv.FormatInt(nextTicket+1, 10))
+			data, err := abandoned.Data()
+			if err != nil || data == 0 {
+				return err
+			}
+			if err := abandoned.Delete(); err != nil {
+				return err
+			}
+		}
+	})
+}
@@ -0,0 +1,87 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test that FairLock grants the lock in order of arrival and skips waiters that give up.
+func TestFairLock(t *testing.T) {
+	NewConn().Node("^fairlock").DeleteTree()
+	holder := NewConn().Node("^fairlock")
+	release, err := holder.FairLock(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var mu sync.Mutex
+	var order []int
+	var wg sync.WaitGroup
+	const waiters = 8
+	for i := range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx := context.Background()
+			if i == 3 {
+				// This waiter gives up while queued and must not hold up those behind it
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, 30*time.Millisecond)
+				defer cancel()
+			}
+			release, err := NewConn().Node("^fairlock").FairLock(ctx)
+			if err != nil {
+				if i != 3 {
+					t.Error(err)
+				}
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			if err := release(); err != nil {
+				t.Error(err)
+			}
+		}()
+		time.Sleep(10 * time.Millisecond) // make waiters arrive in order
+	}
+	time.Sleep(50 * time.Millisecond)
+	// A release that fails, here because it is first tried in a read-only probe (see SetSkipReadOnlyTP()), keeps the
+	// lock so that calling it again releases it
+	var errs []error
+	holder.conn.SetSkipReadOnlyTP(true)
+	holder.conn.Transaction(func(conn *Conn) error {
+		err := release()
+		errs = append(errs, err)
+		return err
+	})
+	holder.conn.SetSkipReadOnlyTP(false)
+	if len(errs) != 2 || errs[0] == nil || errs[1] != nil {
+		t.Errorf("got release errors %v, want a failure then success", errs)
+	}
+	if err := release(); err != nil { // no further effect
+		t.Error(err)
+	}
+	wg.Wait()
+	if want := []int{0, 1, 2, 4, 5, 6, 7}; !slices.Equal(order, want) {
+		t.Errorf("got acquisition order %v, want %v", order, want)
+	}
+	if _, err := holder.FairLock(context.Background()); err != nil {
+		t.Errorf("got error %v acquiring after all releases", err)
+	}
+}
@@ -0,0 +1,84 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Store and retrieve times in M's $HOROLOG format.
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidHorolog is returned by GetTime() when the value of a node is not in $HOROLOG format.
+var ErrInvalidHorolog = errors.New("YDB: invalid $HOROLOG value")
+
+// horologEpoch is day 0 of $HOROLOG.
+var horologEpoch = time.Date(1840, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+// SetTime sets the value of n to time t in M's `$HOROLOG` format "days,seconds": the number of days since 31 December
+// 1840 and the number of seconds since midnight. Fractions of a second are truncated.
+// Like M's $HOROLOG, the value represents local wall-clock time, so t is first converted to Go's time.Local, which
+// is normally the time zone of the process (see the TZ environment variable).
+func (n *Node) SetTime(t time.Time) error {
+	return n.Set(horolog(t))
+}
+
+// GetTime returns the time stored in n in M's `$HOROLOG` format, as set by SetTime() or by M code using $HOROLOG.
+// The value is interpreted as wall-clock time in Go's time.Local.
+// Since $HOROLOG records wall-clock time without a time zone offset, times during the hour repeated at the end of
+// daylight saving time (when clocks go back by an hour) are ambiguous, and GetTime returns the earlier of the two;
+// likewise a time in the hour skipped at the start of daylight saving time is moved forward by Go's time.Date().
+// If the value is not in $HOROLOG format, GetTime returns an error that wraps ErrInvalidHorolog.
+func (n *Node) GetTime() (time.Time, error) {
+	val, err := n.Get()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parseHorolog(val)
+}
+
+// horolog returns t as local time in $HOROLOG format.
+func horolog(t time.Time) string {
+	t = t.In(time.Local)
+	year, month, day := t.Date()
+	days := (time.Date(year, month, day, 0, 0, 0, 0, time.UTC).Unix() - horologEpoch.Unix()) / (24 * 60 * 60)
+	hour, minute, second := t.Clock()
+	return strconv.FormatInt(days, 10) + "," + strconv.Itoa(hour*3600+minute*60+second)
+}
+
+// parseHorolog returns the local time represented by $HOROLOG value h.
+func parseHorolog(h string) (time.Time, error) {
+	dayStr, secStr, ok := strings.Cut(h, ",")
+	days, err1 := strconv.Atoi(dayStr)
+	secs, err2 := strconv.Atoi(secStr)
+	if !ok || err1 != nil || err2 != nil || secs < 0 || secs >= 24*60*60 {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrInvalidHorolog, h)
+	}
+	year, month, day := horologEpoch.AddDate(0, 0, days).Date()
+	t := time.Date(year, month, day, 0, 0, secs, 0, time.Local)
+	// time.Date() does not guarantee which of two times with the same wall clock it returns, so choose the earlier
+	if earlier := t.Add(-time.Hour); sameWallClock(earlier, t) {
+		return earlier, nil
+	}
+	return t, nil
+}
+
+// sameWallClock reports whether a and b have the same date and time of day in their own time zones.
+func sameWallClock(a, b time.Time) bool {
+	y1, m1, d1 := a.Date()
+	y2, m2, d2 := b.Date()
+	return y1 == y2 && m1 == m2 && d1 == d2 && a.Hour() == b.Hour() && a.Minute() == b.Minute() && a.Second() == b.Second()
+}
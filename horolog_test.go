@@ -0,0 +1,103 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test round-tripping times through SetTime and GetTime in $HOROLOG format.
+func TestTime(t *testing.T) {
+	saved := time.Local
+	defer func() { time.Local = saved }()
+	time.Local = time.UTC
+
+	conn := NewConn()
+	n := conn.Node("^time")
+	for _, test := range []struct {
+		t       time.Time
+		horolog string
+	}{
+		{time.Date(1840, time.December, 31, 0, 0, 0, 0, time.UTC), "0,0"},
+		{time.Date(1841, time.January, 1, 23, 59, 59, 0, time.UTC), "1,86399"},
+		{time.Date(2000, time.February, 29, 12, 0, 0, 0, time.UTC), "58133,43200"},
+		{time.Date(2025, time.January, 1, 1, 2, 3, 0, time.UTC), "67206,3723"},
+		{time.Date(2200, time.July, 4, 0, 0, 1, 0, time.UTC), "131307,1"},
+	} {
+		t.Run(test.horolog, func(t *testing.T) {
+			if err := n.SetTime(test.t); err != nil {
+				t.Fatal(err)
+			}
+			if val := n.MustGet(); val != test.horolog {
+				t.Errorf("got %s, want %s", val, test.horolog)
+			}
+			got, err := n.GetTime()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !got.Equal(test.t) {
+				t.Errorf("got %v, want %v", got, test.t)
+			}
+		})
+	}
+
+	t.Run("DST", func(t *testing.T) {
+		loc, err := time.LoadLocation("America/New_York")
+		if err != nil {
+			t.Skip("time zone database not available:", err)
+		}
+		time.Local = loc
+		// 1:30am occurs twice on 2 November 2025 in New York; both store the same wall-clock $HOROLOG.
+		first := time.Date(2025, time.November, 2, 5, 30, 0, 0, time.UTC)
+		second := first.Add(time.Hour)
+		n.SetTime(first)
+		h1 := n.MustGet()
+		n.SetTime(second)
+		if h2 := n.MustGet(); h1 != h2 || h1 != "67511,5400" {
+			t.Errorf("got %s and %s, want both %s", h1, h2, "67511,5400")
+		}
+		got, _ := n.GetTime()
+		if !got.Equal(first) {
+			t.Errorf("got %v, want the earlier time %v", got, first)
+		}
+		// Times that are not ambiguous, just after the repeated hour and a day before it, are not moved.
+		for _, want := range []time.Time{second.Add(time.Hour), first.AddDate(0, 0, -1)} {
+			n.SetTime(want)
+			if got, _ := n.GetTime(); !got.Equal(want) {
+				t.Errorf("got %v, want %v", got, want)
+			}
+		}
+		// A time from another zone is stored as local wall-clock time.
+		n.SetTime(time.Date(2025, time.January, 1, 12, 0, 0, 0, time.UTC))
+		if val := n.MustGet(); val != "67206,25200" {
+			t.Errorf("got %s, want %s", val, "67206,25200")
+		}
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for _, val := range []string{"", "67000", "x,1", "1,86400", "1,-1"} {
+			n.Set(val)
+			if _, err := n.GetTime(); !errors.Is(err, ErrInvalidHorolog) {
+				t.Errorf("got error %v for %q, want %v", err, val, ErrInvalidHorolog)
+			}
+		}
+	})
+
+	t.Run("HOROLOG", func(t *testing.T) {
+		if _, err := conn.Node("$HOROLOG").GetTime(); err != nil {
+			t.Error(err)
+		}
+	})
+}
@@ -0,0 +1,570 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Iterators over database names and nodes.
+// Since Go iterators cannot return an error, iterators panic on any unexpected YottaDB error. Within a transaction, a
+// panic with the YDB_TP_RESTART error that YottaDB returns on detecting a conflict restarts the transaction (see
+// Transaction()), so iterators may be used within transactions like other database operations.
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"strings"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// ErrNodeEnd is returned by SubscriptNext(), SubscriptPrevious(), NodeNext() and NodePrevious() when there are no more
+// subscripts or nodes, so that loops calling them can stop when errors.Is(err, ErrNodeEnd), like io.EOF.
+// These methods return ErrNodeEnd itself rather than the equivalent YottaDB error YDB_ERR_NODEEND, which ErrNodeEnd
+// also matches using errors.Is().
+var ErrNodeEnd error = &YDBError{int(C.YDB_ERR_NODEEND), "YDB: no more subscripts or nodes"}
+
+// SubscriptNext returns the subscript of the next sibling of n in collation order; that is, the next subscript at
+// the level of the last subscript of n. If the last subscript of n is "", it returns the first subscript at that level.
+// If n has no subscripts, it returns the next variable name instead.
+// When there are no more subscripts it returns ErrNodeEnd.
+func (n *Node) SubscriptNext() (string, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	ret := C.ydb_subscript_next_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &conn.value)
+	if ret == C.YDB_ERR_NODEEND {
+		return "", ErrNodeEnd
+	}
+	if ret != C.YDB_OK {
+		return "", n.conn.Error(ret)
+	}
+	return C.GoStringN(conn.value.buf_addr, C.int(conn.value.len_used)), nil
+}
+
+// SubscriptPrevious returns the subscript of the previous sibling of n in collation order.
+// If the last subscript of n is "", it returns the last subscript at that level.
+// Otherwise it behaves like SubscriptNext().
+func (n *Node) SubscriptPrevious() (string, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	ret := C.ydb_subscript_previous_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &conn.value)
+	if ret == C.YDB_ERR_NODEEND {
+		return "", ErrNodeEnd
+	}
+	if ret != C.YDB_OK {
+		return "", n.conn.Error(ret)
+	}
+	return C.GoStringN(conn.value.buf_addr, C.int(conn.value.len_used)), nil
+}
+
+// varnames returns an iterator over all variable names that follow first in collation order, including first itself if it exists.
+func (conn *Conn) varnames(first string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		n := conn.Node(first)
+		data, err := n.Data()
+		if err != nil {
+			panic(err)
+		}
+		if data != 0 && !yield(first) {
+			return
+		}
+		for {
+			name, err := n.SubscriptNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			if !yield(name) {
+				return
+			}
+			n = conn.Node(name)
+		}
+	}
+}
+
+// SubscriptNext returns the name of the variable that follows varname in collation order, which is what the
+// SubscriptNext() method returns for a node with no subscripts. This makes variable names the top level of the same
+// iteration scheme as subscripts.
+// Iteration is seeded with "": SubscriptNext("") returns the first global variable name. Global names collate in byte
+// order of their ASCII characters, so "^%" is the first possible global name and the seed starts from there.
+// Pass a global name to get the next global, or a local name to get the next local variable.
+// When there are no more names it returns ErrNodeEnd.
+func (conn *Conn) SubscriptNext(varname string) (string, error) {
+	if varname == "" {
+		n := conn.Node("^%")
+		data, err := n.Data()
+		if err != nil || data != 0 {
+			return "^%", err
+		}
+		return n.SubscriptNext()
+	}
+	return conn.Node(varname).SubscriptNext()
+}
+
+// GlobalNames returns an iterator over the names of all global variables in the current global directory, in collation order.
+// Each name includes its leading "^". It iterates using conn.SubscriptNext() seeded with "".
+func (conn *Conn) GlobalNames() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		name := ""
+		for {
+			var err error
+			name, err = conn.SubscriptNext(name)
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			if !yield(name) {
+				return
+			}
+		}
+	}
+}
+
+// LocalNames returns an iterator over the names of all local variables of this process, in collation order.
+// Intrinsic special variables (ISVs such as $ZGBLDIR) are not variables and are not included.
+func (conn *Conn) LocalNames() iter.Seq[string] {
+	return conn.varnames("%")
+}
+
+// initialSubsSize is the initial space allocated for each subscript returned by ydb_node_next_st().
+// If a longer subscript is returned, the space for that subscript is enlarged to fit.
+const initialSubsSize = 256
+
+// NodeNext returns the next node after n that has a value, in depth-first collation order like M's `$QUERY(node)`.
+// The returned node may be deeper or shallower than n, but always has the same varname.
+// When there are no more nodes it returns ErrNodeEnd.
+func (n *Node) NodeNext() (*Node, error) {
+	return n.nodeNext(false)
+}
+
+// NodePrevious returns the previous node before n that has a value, in depth-first collation order like M's `$QUERY(node,-1)`.
+// Like NodeNext(), the returned node may be deeper or shallower than n, including the unsubscripted variable itself.
+// Otherwise it behaves like NodeNext().
+func (n *Node) NodePrevious() (*Node, error) {
+	return n.nodeNext(true)
+}
+
+// nodeNext implements NodeNext() and NodePrevious(), returning subscripts in the connection's subs buffers.
+func (n *Node) nodeNext(reverse bool) (*Node, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	if conn.subs[0].buf_addr == nil {
+		for i := range conn.subs {
+			conn.subs[i].buf_addr = (*C.char)(C.malloc(initialSubsSize))
+			conn.subs[i].len_alloc = initialSubsSize
+			conn.subs[i].len_used = 0
+		}
+	}
+	for {
+		nsubs := C.int(C.YDB_MAX_SUBS)
+		var ret C.int
+		if reverse {
+			ret = C.ydb_node_previous_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &nsubs, &conn.subs[0])
+		} else {
+			ret = C.ydb_node_next_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &nsubs, &conn.subs[0])
+		}
+		if ret == C.YDB_ERR_INVSTRLEN {
+			// Subscript number nsubs was too long to fit: enlarge its buffer and try again
+			if metricsEnabled.Load() {
+				metrics.Reallocations.Add(1)
+			}
+			buf := &conn.subs[nsubs]
+			size := buf.len_used
+			C.free(unsafe.Pointer(buf.buf_addr))
+			buf.buf_addr = (*C.char)(C.malloc(C.size_t(size)))
+			buf.len_alloc = size
+			continue
+		}
+		if ret == C.YDB_ERR_NODEEND && reverse && c_n.len > 1 {
+			// ydb_node_previous_st() may not return the unsubscripted variable, but $QUERY(node,-1) does if it has a value
+			root := n.derive(nil)
+			if data, err := root.Data(); err == nil && data&1 != 0 {
+				return root, nil
+			}
+		}
+		if ret == C.YDB_ERR_NODEEND {
+			return nil, ErrNodeEnd
+		}
+		if ret != C.YDB_OK {
+			return nil, n.conn.Error(ret)
+		}
+		// The number of subscripts returned, nsubs, may differ from the number in n since the node found may be at any depth
+		subs := make([]string, nsubs)
+		for i := range subs {
+			subs[i] = C.GoStringN(conn.subs[i].buf_addr, C.int(conn.subs[i].len_used))
+		}
+		return n.derive(subs), nil
+	}
+}
+
+// Tree returns an iterator over n and all of its descendants that have a value, in depth-first collation order.
+// Each yielded node is a new immutable Node.
+func (n *Node) Tree() iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		data, err := n.Data()
+		if err != nil {
+			panic(err)
+		}
+		if data&1 != 0 && !yield(n) {
+			return
+		}
+		root := n.subscripts()
+		for node := n; ; {
+			node, err = node.NodeNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			subs := node.subscripts()
+			if len(subs) <= len(root) || collateSubscripts(subs[:len(root)], root) != 0 {
+				return // passed the end of n's subtree
+			}
+			if !yield(node) {
+				return
+			}
+		}
+	}
+}
+
+// TreeDepth returns an iterator over n and those of its descendants that have a value, like Tree(), but without
+// descending more than maxDepth levels below n, such as to summarise the top levels of a large, deeply nested tree.
+// A maxDepth of 0 yields only n (if it has a value), 1 also yields its children, and so on; a negative maxDepth
+// yields the whole subtree. Rather than skip the deeper nodes that NodeNext() would visit, TreeDepth iterates
+// the children at each level with SubscriptNext(), so it never visits the levels beyond maxDepth.
+// Each yielded node is a new immutable Node.
+func (n *Node) TreeDepth(maxDepth int) iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		n.treeDepth(maxDepth, yield)
+	}
+}
+
+// treeDepth yields n and its descendants up to depth levels below it for TreeDepth(), and reports whether to continue.
+func (n *Node) treeDepth(depth int, yield func(*Node) bool) bool {
+	data, err := n.Data()
+	if err != nil {
+		panic(err)
+	}
+	if data&1 != 0 && !yield(n) {
+		return false
+	}
+	if depth == 0 || data < 10 {
+		return true
+	}
+	for sub := range n.ChildSubscripts() {
+		if !n.Child(sub).treeDepth(depth-1, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// Children returns an iterator over the immediate children of n in collation order, whether or not they have a value.
+// This includes the reserved MetaSubscript child that holds n's metadata, if any; use ChildrenSkipMeta() to skip it.
+// For speed, each yielded node is the same mutable Node with its last subscript changed; it is only valid until the
+// next iteration. Use Node.Copy() to retain it.
+func (n *Node) Children() iter.Seq[*Node] {
+	return n.children(false)
+}
+
+// ChildrenSkipMeta returns an iterator over the immediate children of n like Children(), but skips the reserved
+// MetaSubscript child that holds n's metadata, so that only the application's children are yielded.
+func (n *Node) ChildrenSkipMeta() iter.Seq[*Node] {
+	return n.children(true)
+}
+
+// children implements Children() and ChildrenSkipMeta(), skipping the MetaSubscript child if skipMeta is set.
+func (n *Node) children(skipMeta bool) iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		for _, child := range n.childrenWithSub(skipMeta) {
+			if !yield(child) {
+				return
+			}
+		}
+	}
+}
+
+// ChildrenWithSub returns an iterator over the immediate children of n like Children(), but yields the last subscript
+// of each child together with the child, which is convenient for building maps keyed by subscript.
+// As for Children(), the yielded node is the same mutable Node each time and is only valid until the next iteration,
+// whereas the subscript remains valid.
+func (n *Node) ChildrenWithSub() iter.Seq2[string, *Node] {
+	return n.childrenWithSub(false)
+}
+
+// childrenWithSub implements ChildrenWithSub(), skipping the MetaSubscript child if skipMeta is set, as the collection
+// functions do.
+func (n *Node) childrenWithSub(skipMeta bool) iter.Seq2[string, *Node] {
+	return func(yield func(string, *Node) bool) {
+		child := &Node{conn: n.conn, collation: n.collation}
+		child.alloc(n.varname(), append(n.subscripts(), ""), mutableSpare)
+		for {
+			sub, err := child.SubscriptNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			child.setLastSubscript(sub)
+			if skipMeta && sub == MetaSubscript {
+				continue
+			}
+			if !yield(sub, child) {
+				return
+			}
+		}
+	}
+}
+
+// ChildSubscripts returns an iterator over the subscripts of the immediate children of n in collation order.
+// It is like Children() but yields only the last subscript of each child.
+func (n *Node) ChildSubscripts() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		child := &Node{conn: n.conn, collation: n.collation}
+		child.alloc(n.varname(), append(n.subscripts(), ""), mutableSpare)
+		for {
+			sub, err := child.SubscriptNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			if !yield(sub) {
+				return
+			}
+			child.setLastSubscript(sub)
+		}
+	}
+}
+
+// ChildrenInRange returns an iterator over the immediate children of n whose subscripts lie within the inclusive range
+// [from, to] in collation order, whether or not they have a value. For example, on a global subscripted by integer
+// timestamps, ChildrenInRange("100", "200") yields the children from 100 to 200 including both ends if they exist.
+// Neither from nor to needs to exist as a child. If from collates after to, the range is empty and nothing is yielded.
+// Like Children(), each yielded node is the same mutable Node and is only valid until the next iteration.
+func (n *Node) ChildrenInRange(from, to string) iter.Seq[*Node] {
+	return func(yield func(*Node) bool) {
+		if n.collate(from, to) > 0 {
+			return
+		}
+		child := &Node{conn: n.conn, collation: n.collation}
+		child.alloc(n.varname(), append(n.subscripts(), from), mutableSpare)
+		if from != "" {
+			data, err := child.Data()
+			if err != nil {
+				panic(err)
+			}
+			if data != 0 && !yield(child) {
+				return
+			}
+		}
+		for {
+			sub, err := child.SubscriptNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			if n.collate(sub, to) > 0 {
+				return
+			}
+			child.setLastSubscript(sub)
+			if !yield(child) {
+				return
+			}
+		}
+	}
+}
+
+// ChildrenWithPrefix returns an iterator over the immediate children of n whose subscripts are strings that start with
+// prefix, such as to autocomplete a key. It starts at prefix rather than at the first child, and stops at the first
+// child after that no longer starts with prefix, relying on YottaDB's default collation of strings in byte order.
+// Subscripts that are canonical numbers, such as "12", collate in numeric order before all strings, so they are not
+// contiguous by prefix and are never yielded, unless prefix is "", which matches every child. For example, with children
+// 1, 12, 120, "12a" and "12b", ChildrenWithPrefix("12") yields only "12a" and "12b". A non-canonical number such as
+// "012" or "1.0" is a string, so it is matched like any other string.
+// Like Children(), each yielded node is the same mutable Node and is only valid until the next iteration.
+func (n *Node) ChildrenWithPrefix(prefix string) iter.Seq[*Node] {
+	if prefix == "" {
+		return n.Children()
+	}
+	return func(yield func(*Node) bool) {
+		seed := prefix
+		if isCanonicalNumber(prefix) {
+			// Seed with a string just before prefix in byte order, since prefix itself collates among the numbers
+			seed = prefix[:len(prefix)-1] + string(prefix[len(prefix)-1]-1) + "\xff"
+		}
+		child := &Node{conn: n.conn, collation: n.collation}
+		child.alloc(n.varname(), append(n.subscripts(), seed), mutableSpare)
+		if seed == prefix {
+			data, err := child.Data()
+			if err != nil {
+				panic(err)
+			}
+			if data != 0 && !yield(child) {
+				return
+			}
+		}
+		for {
+			sub, err := child.SubscriptNext()
+			if errors.Is(err, ErrNodeEnd) {
+				return
+			}
+			if err != nil {
+				panic(err)
+			}
+			child.setLastSubscript(sub)
+			if isCanonicalNumber(sub) || sub < prefix {
+				continue // still before the strings that start with prefix
+			}
+			if !strings.HasPrefix(sub, prefix) || !yield(child) {
+				return
+			}
+		}
+	}
+}
+
+// isCanonicalNumber reports whether s is a number in YottaDB canonical form: an optional "-", then digits without
+// leading zeros and/or a fraction without trailing zeros, with at most MaxNumericDigits significant digits. For example
+// "12", "-.5", "0" and "1000000000000000000", but not "012", "1.0", "-0" or "1234567890123456789".
+func isCanonicalNumber(s string) bool {
+	if s == "0" {
+		return true
+	}
+	s = strings.TrimPrefix(s, "-")
+	intPart, fracPart, hasDot := strings.Cut(s, ".")
+	if intPart == "" && fracPart == "" || hasDot && fracPart == "" {
+		return false
+	}
+	if strings.HasPrefix(intPart, "0") || strings.HasSuffix(fracPart, "0") || significantDigits(s) > MaxNumericDigits {
+		return false
+	}
+	for _, c := range intPart + fracPart {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// numberSign returns -1, 0 or +1 according to the sign of canonical number s.
+func numberSign(s string) int {
+	switch {
+	case s == "0":
+		return 0
+	case strings.HasPrefix(s, "-"):
+		return -1
+	}
+	return 1
+}
+
+// compareNumbers compares two canonical numbers exactly, returning -1, 0 or +1.
+func compareNumbers(a, b string) int {
+	signA, signB := numberSign(a), numberSign(b)
+	if signA != signB || signA == 0 {
+		return max(-1, min(signA-signB, 1))
+	}
+	intA, fracA, _ := strings.Cut(strings.TrimPrefix(a, "-"), ".")
+	intB, fracB, _ := strings.Cut(strings.TrimPrefix(b, "-"), ".")
+	cmp := max(-1, min(len(intA)-len(intB), 1))
+	if cmp == 0 {
+		cmp = strings.Compare(intA, intB)
+	}
+	if cmp == 0 {
+		cmp = strings.Compare(fracA, fracB)
+	}
+	return cmp * signA
+}
+
+// Collate compares two subscripts in YottaDB's default collation order, returning -1, 0 or +1:
+// the empty string first, then canonical numbers in numeric order, then other strings in byte order.
+func Collate(a, b string) int {
+	if a == "" || b == "" {
+		return strings.Compare(a, b)
+	}
+	numA, numB := isCanonicalNumber(a), isCanonicalNumber(b)
+	switch {
+	case numA && numB:
+		return compareNumbers(a, b)
+	case numA:
+		return -1
+	case numB:
+		return 1
+	}
+	return strings.Compare(a, b)
+}
+
+// collations holds the comparison function registered for each alternative collation id by RegisterCollation().
+var collations sync.Map
+
+// RegisterCollation registers cmp as the comparison of subscripts, returning -1, 0 or +1 like Collate(), for nodes
+// created with NodeOptions.Collation set to id. It should match the order of the alternative collation sequence that
+// YottaDB is configured to use with id. id must be from 1 to 255: 0 is YottaDB's standard collation, Collate().
+func RegisterCollation(id int, cmp func(a, b string) int) {
+	if id < 1 || id > 255 {
+		panic(fmt.Sprintf("YDB: invalid collation id %d", id))
+	}
+	collations.Store(id, cmp)
+}
+
+// collationFunc returns the comparison function for collation id, or nil if it is not registered.
+func collationFunc(id int) func(a, b string) int {
+	if id == 0 {
+		return Collate
+	}
+	cmp, ok := collations.Load(id)
+	if !ok {
+		return nil
+	}
+	return cmp.(func(a, b string) int)
+}
+
+// collate compares two subscripts of n's variable in the order of its collation sequence.
+func (n *Node) collate(a, b string) int {
+	if n.collation == 0 {
+		return Collate(a, b)
+	}
+	return collationFunc(n.collation)(a, b)
+}
+
+// collateSubscripts compares two lists of subscripts in YottaDB collation order, returning -1, 0 or +1.
+// A list collates before any longer list that it is a prefix of, just as a node collates before its descendants.
+func collateSubscripts(a, b []string) int {
+	for i := range min(len(a), len(b)) {
+		if cmp := Collate(a[i], b[i]); cmp != 0 {
+			return cmp
+		}
+	}
+	return max(-1, min(len(a)-len(b), 1))
+}
@@ -0,0 +1,388 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Test enumeration of global variable names.
+func TestGlobalNames(t *testing.T) {
+	conn := NewConn()
+	for _, name := range []string{"^gnamesB", "^gnamesA"} {
+		if err := conn.Node(name).Set("x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := slices.Collect(conn.GlobalNames())
+	a := slices.Index(names, "^gnamesA")
+	b := slices.Index(names, "^gnamesB")
+	if a < 0 || b < 0 {
+		t.Fatalf("got %v, want it to include ^gnamesA and ^gnamesB", names)
+	}
+	if a > b {
+		t.Errorf("got ^gnamesA after ^gnamesB in %v", names)
+	}
+	if !slices.IsSorted(names) {
+		t.Errorf("got unsorted names %v", names)
+	}
+}
+
+// Test iterating over global names using SubscriptNext at the level of variable names.
+func TestConnSubscriptNext(t *testing.T) {
+	conn := NewConn()
+	conn.Node("^cnextA").Set("x")
+	conn.Node("^cnextB", "sub").Set("x") // a global with no value at its root is still a name
+	var names []string
+	for name := ""; ; {
+		var err error
+		name, err = conn.SubscriptNext(name)
+		if errors.Is(err, ErrNodeEnd) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	if want := slices.Collect(conn.GlobalNames()); !slices.Equal(names, want) {
+		t.Errorf("got %v, want %v", names, want)
+	}
+	if !slices.Contains(names, "^cnextA") || !slices.Contains(names, "^cnextB") {
+		t.Errorf("got %v, want it to include ^cnextA and ^cnextB", names)
+	}
+	// A node with no subscripts iterates the same way
+	if got, err := conn.Node("^cnextA").SubscriptNext(); err != nil || got != "^cnextB" {
+		t.Errorf("got %s, %v, want %s", got, err, "^cnextB")
+	}
+}
+
+// Test enumeration of local variable names.
+func TestLocalNames(t *testing.T) {
+	conn := NewConn()
+	for _, name := range []string{"lnamesC", "lnamesA", "lnamesB"} {
+		if err := conn.Node(name).Set("x"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	names := slices.Collect(conn.LocalNames())
+	for _, name := range []string{"lnamesA", "lnamesB", "lnamesC"} {
+		if !slices.Contains(names, name) {
+			t.Errorf("got %v, want it to include %s", names, name)
+		}
+	}
+	for _, name := range names {
+		if name[0] == '$' || name[0] == '^' {
+			t.Errorf("got non-local name %s", name)
+		}
+	}
+
+	if err := conn.Node("lnamesB").DeleteTree(); err != nil {
+		t.Fatal(err)
+	}
+	names = slices.Collect(conn.LocalNames())
+	if slices.Contains(names, "lnamesB") {
+		t.Errorf("got %v, want lnamesB to be deleted", names)
+	}
+	if !slices.Contains(names, "lnamesA") || !slices.Contains(names, "lnamesC") {
+		t.Errorf("got %v, want it to include lnamesA and lnamesC", names)
+	}
+}
+
+// Test iterating over sibling subscripts.
+func TestSubscriptNext(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^subnext")
+	n.DeleteTree()
+	for _, sub := range []string{"b", "2", "a", "10"} {
+		n.Child(sub).Set("x")
+	}
+	collect := func(next func(*Node) (string, error)) []string {
+		var subs []string
+		child := n.Child("")
+		for {
+			sub, err := next(child)
+			if errors.Is(err, ErrNodeEnd) {
+				return subs
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			subs = append(subs, sub)
+			child = n.Child(sub)
+		}
+	}
+	want := []string{"2", "10", "a", "b"}
+	if got := collect((*Node).SubscriptNext); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	slices.Reverse(want)
+	if got := collect((*Node).SubscriptPrevious); !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// Test iterating over children together with their subscripts.
+func TestChildrenWithSub(t *testing.T) {
+	n := NewConn().Node("^withsub")
+	setTree(t, n, map[string]string{"a": "1", "b": "2", "b,x": "3", "10": "4"})
+	n.SetMeta("version", "1")
+	got := map[string]string{}
+	var order []string
+	for sub, child := range n.ChildrenWithSub() {
+		got[sub] = child.MustGet("")
+		order = append(order, sub)
+	}
+	if want := map[string]string{"10": "4", MetaSubscript: "", "a": "1", "b": "2"}; !maps.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if want := []string{"10", MetaSubscript, "a", "b"}; !slices.Equal(order, want) {
+		t.Errorf("got order %v, want %v", order, want)
+	}
+}
+
+// Test that each iteration method terminates with exactly ErrNodeEnd.
+func TestNodeEnd(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^nodeend")
+	n.DeleteTree()
+	n.Child("only").Set("x")
+	last := n.Child("only")
+	for name, next := range map[string]func() error{
+		"SubscriptNext":     func() error { _, err := last.SubscriptNext(); return err },
+		"SubscriptPrevious": func() error { _, err := last.SubscriptPrevious(); return err },
+		"NodeNext":          func() error { _, err := last.NodeNext(); return err },
+		"NodePrevious":      func() error { _, err := last.NodePrevious(); return err },
+		"Conn.SubscriptNext": func() error {
+			_, err := conn.SubscriptNext("^" + strings.Repeat("z", 31))
+			return err
+		},
+	} {
+		if err := next(); err != ErrNodeEnd {
+			t.Errorf("%s: got error %v, want ErrNodeEnd", name, err)
+		}
+	}
+	if !errors.Is(Error(YDB_ERR_NODEEND, "%YDB-E-NODEEND"), ErrNodeEnd) {
+		t.Errorf("got YDB_ERR_NODEEND not matching ErrNodeEnd")
+	}
+}
+
+// Test node traversal with NodeNext, NodePrevious and Tree.
+func TestTree(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^tree", "root")
+	setTree(t, n, map[string]string{"a": "1", "a,1": "2", "a,1,x": "3", "b,2": "4", "10": "5"})
+	n.Set("0")
+	conn.Node("^tree", "zafter").Set("outside")
+
+	var got []string
+	for node := range n.Tree() {
+		got = append(got, node.String())
+	}
+	want := []string{`^tree("root")`, `^tree("root")("10")`, `^tree("root")("a")`, `^tree("root")("a")("1")`,
+		`^tree("root")("a")("1")("x")`, `^tree("root")("b")("2")`}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	prev, err := conn.Node("^tree", "root", "b", "2").NodePrevious()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev.String() != want[4] {
+		t.Errorf("got %s, want %s", prev, want[4])
+	}
+	_, err = conn.Node("^tree", "zafter").NodeNext()
+	if !errors.Is(err, ErrNodeEnd) {
+		t.Errorf("got error %v, want ErrNodeEnd", err)
+	}
+}
+
+// Test limiting the depth of a traversal with TreeDepth.
+func TestTreeDepth(t *testing.T) {
+	n := NewConn().Node("^treedepth")
+	setTree(t, n, map[string]string{"a": "1", "a,1": "2", "a,1,x": "3", "a,1,x,deep": "4", "b,2": "5", "b,2,y": "6", "c": "7"})
+	n.Set("0")
+	for _, test := range []struct {
+		maxDepth int
+		want     []string
+	}{
+		{0, []string{""}},
+		{1, []string{"", "a", "c"}},
+		{2, []string{"", "a", "a,1", "b,2", "c"}},
+		{-1, []string{"", "a", "a,1", "a,1,x", "a,1,x,deep", "b,2", "b,2,y", "c"}},
+	} {
+		var got []string
+		for node := range n.TreeDepth(test.maxDepth) {
+			got = append(got, strings.Join(node.subscripts(), ","))
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("maxDepth %d: got %q, want %q", test.maxDepth, got, test.want)
+		}
+	}
+}
+
+// Test that NodePrevious returns the full subscripts of previous nodes at a different depth from the seed.
+func TestNodePreviousDepth(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^qprev")
+	setTree(t, n, map[string]string{"1": "a", "1,2,3": "deep", "2": "b", "2,1": "c"})
+	n.Set("root")
+	for _, test := range []struct {
+		seed []string
+		want []string
+	}{
+		{[]string{"2"}, []string{"1", "2", "3"}},           // deeper
+		{[]string{"1", "2", "3"}, []string{"1"}},           // shallower
+		{[]string{"1"}, []string{}},                        // the unsubscripted variable
+		{[]string{"3"}, []string{"2", "1"}},                // seed without a value
+		{[]string{"2", "1", "0", "x"}, []string{"2", "1"}}, // seed deeper than the result
+	} {
+		prev, err := conn.Node("^qprev", test.seed...).NodePrevious()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := prev.subscripts(); !slices.Equal(got, test.want) {
+			t.Errorf("previous of %v: got subscripts %q, want %q", test.seed, got, test.want)
+		}
+	}
+	if _, err := n.NodePrevious(); !errors.Is(err, ErrNodeEnd) {
+		t.Errorf("got error %v, want ErrNodeEnd", err)
+	}
+
+	// Walking backwards visits every node that walking forwards does, in reverse.
+	var forward, backward []string
+	for node := range n.Tree() {
+		forward = append(forward, node.String())
+	}
+	for node, err := conn.Node("^qprev", "3"), error(nil); ; {
+		node, err = node.NodePrevious()
+		if errors.Is(err, ErrNodeEnd) {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		backward = append(backward, node.String())
+	}
+	slices.Reverse(backward)
+	if !slices.Equal(backward, forward) {
+		t.Errorf("got %v, want %v", backward, forward)
+	}
+}
+
+// Test iterating over immediate children with Children, and retaining them with Copy.
+func TestChildren(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^children", "root")
+	long := strings.Repeat("x", 2*mutableSpare) // longer than the spare space so the node must be reallocated
+	setTree(t, n, map[string]string{"a,1": "1", "b": "2", "10": "3", long: "4", "c": "5"})
+
+	var got []string
+	var kept []*Node
+	for child := range n.Children() {
+		got = append(got, child.String())
+		kept = append(kept, child.Copy())
+	}
+	want := []string{`^children("root")("10")`, `^children("root")("a")`, `^children("root")("b")`,
+		`^children("root")("c")`, `^children("root")("` + long + `")`}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for i, node := range kept {
+		if node.String() != want[i] {
+			t.Errorf("got %s, want %s", node, want[i])
+		}
+	}
+}
+
+// Test iterating over a range of children with ChildrenInRange.
+func TestChildrenInRange(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^range")
+	n.DeleteTree()
+	for i := 1; i <= 20; i++ {
+		n.Child(strconv.Itoa(i)).Set("x")
+	}
+	n.Child("a").Set("x")
+	for _, test := range []struct {
+		from, to string
+		want     []string
+	}{
+		{"5", "9", []string{"5", "6", "7", "8", "9"}},
+		{"5.5", "7", []string{"6", "7"}},
+		{"", "2", []string{"1", "2"}},
+		{"19", "zzz", []string{"19", "20", "a"}},
+		{"7", "7", []string{"7"}},
+		{"21", "30", nil},
+		{"9", "5", nil},
+	} {
+		t.Run(test.from+"-"+test.to, func(t *testing.T) {
+			var got []string
+			for child := range n.ChildrenInRange(test.from, test.to) {
+				got = append(got, child.subscripts()[0])
+			}
+			if !slices.Equal(got, test.want) {
+				t.Errorf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+// Test iterating over children whose subscripts start with a prefix.
+func TestChildrenWithPrefix(t *testing.T) {
+	n := NewConn().Node("^prefix")
+	setTree(t, n, map[string]string{"1": "x", "12": "x", "120": "x", "12a": "x", "12b": "x", "12b,sub": "x", "13": "x",
+		"ab": "x", "abc": "x", "abd": "x", "ac": "x", "b": "x", "1.0": "x"})
+	n.Child("abe", "sub").Set("x") // a child with no value of its own
+	for _, test := range []struct {
+		prefix string
+		want   []string
+	}{
+		{"ab", []string{"ab", "abc", "abd", "abe"}},
+		{"abc", []string{"abc"}},
+		{"abz", nil},
+		{"z", nil},
+		{"12", []string{"12a", "12b"}},
+		{"1", []string{"1.0", "12a", "12b"}},
+		{"", []string{"1", "12", "13", "120", "1.0", "12a", "12b", "ab", "abc", "abd", "abe", "ac", "b"}},
+	} {
+		var got []string
+		for child := range n.ChildrenWithPrefix(test.prefix) {
+			got = append(got, child.subscripts()[0])
+		}
+		if !slices.Equal(got, test.want) {
+			t.Errorf("prefix %q: got %v, want %v", test.prefix, got, test.want)
+		}
+	}
+}
+
+// Test YottaDB collation order of subscripts.
+func TestCollate(t *testing.T) {
+	ordered := []string{"", "-10", "-1.5", "-1", "-.5", "-.0000000000000000001", "0", ".0000000000000000001", ".25", ".5",
+		"1", "2", "10", "123456789012345678", "1000000000000000000", "-0", "00", "1.0", "1234567890123456789", "A", "a",
+		"ab", "b"}
+	for i := range ordered {
+		for j := range ordered {
+			want := max(-1, min(i-j, 1))
+			if got := Collate(ordered[i], ordered[j]); got != want {
+				t.Errorf("Collate(%q, %q): got %d, want %d", ordered[i], ordered[j], got, want)
+			}
+		}
+	}
+}
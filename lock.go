@@ -0,0 +1,161 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Implement YottaDB locks on database nodes
+
+package yottadb
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// ErrLockTimeout is returned by Lock() when the lock could not be acquired within the timeout.
+var ErrLockTimeout error = &YDBError{int(C.YDB_LOCK_TIMEOUT), "YDB: timed out waiting for lock"}
+
+// maxTimeout is the longest timeout that YottaDB accepts (about 24 days).
+const maxTimeout = time.Duration(C.YDB_MAX_TIME_NSEC)
+
+// lockCounts holds the lock count of each node locked by this process, keyed by Node.String(), since YottaDB does not
+// report it. Like YottaDB locks themselves, the counts belong to the process rather than to a Conn.
+var lockCounts = struct {
+	sync.Mutex
+	m map[string]int
+}{m: make(map[string]int)}
+
+// addLockCount adds delta to the lock count recorded for n, never going below zero, and returns the new count.
+func addLockCount(n *Node, delta int) int {
+	lockCounts.Lock()
+	defer lockCounts.Unlock()
+	key := n.String()
+	count := max(lockCounts.m[key]+delta, 0)
+	if count == 0 {
+		delete(lockCounts.m, key)
+	} else {
+		lockCounts.m[key] = count
+	}
+	return count
+}
+
+// SetDefaultTimeout sets the timeout used by operations on conn that wait, such as Lock(), when they are not given
+// an explicit timeout. A zero duration, the initial setting, means wait indefinitely.
+func (conn *Conn) SetDefaultTimeout(d time.Duration) {
+	conn.timeout = d
+}
+
+// lockWait returns how long a lock operation given the optional timeout should wait, and whether it should wait forever.
+func (conn *Conn) lockWait(timeout []time.Duration) (wait time.Duration, forever bool) {
+	wait = conn.timeout
+	forever = wait == 0
+	if len(timeout) > 0 {
+		wait = timeout[0]
+		forever = false
+	}
+	if forever || wait > maxTimeout {
+		wait = maxTimeout
+	}
+	return max(wait, 0), forever
+}
+
+// Lock increments the lock count of n, like the M command `LOCK +node`, waiting until the lock is acquired or until
+// timeout expires, in which case it returns ErrLockTimeout.
+// If no timeout is given, the connection's default timeout is used (see SetDefaultTimeout()).
+// An explicit timeout of 0 tries once without waiting; timeouts longer than YottaDB's maximum (about 24 days) are reduced to it.
+// Note that YottaDB locks are owned by the process, not by the goroutine or Conn: goroutines within one process do not
+// exclude each other by locking the same node. Locks coordinate between processes.
+func (n *Node) Lock(timeout ...time.Duration) error {
+	if n.conn.probing {
+		return n.conn.probeWrite()
+	}
+	if metricsEnabled.Load() {
+		defer func(start time.Time) { metrics.LockWaits.Observe(time.Since(start)) }(time.Now())
+	}
+	wait, forever := n.conn.lockWait(timeout)
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	for {
+		ret := C.ydb_lock_incr_st(conn.tptoken, &conn.errstr, C.ulonglong(wait.Nanoseconds()), &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)))
+		if ret == C.YDB_LOCK_TIMEOUT {
+			if forever {
+				continue
+			}
+			return ErrLockTimeout
+		}
+		if ret == C.YDB_OK {
+			addLockCount(n, 1)
+		}
+		return n.conn.Error(ret)
+	}
+}
+
+// LockNested is like Lock() but also returns the lock depth of n after locking it, which is 1 if the lock was newly
+// acquired or more if this process already held it, together with a function that releases this level of the lock.
+// This lets layered code tell whether it acquired a lock and release exactly what it took. Calling release more than
+// once has no further effect. On error, depth is 0 and release does nothing.
+// The depth counts locks made by Lock() and LockNested() from any Conn in this process, since locks belong to the process.
+func (n *Node) LockNested(timeout ...time.Duration) (depth int, release func(), err error) {
+	if err := n.Lock(timeout...); err != nil {
+		return 0, func() {}, err
+	}
+	lockCounts.Lock()
+	depth = lockCounts.m[n.String()]
+	lockCounts.Unlock()
+	return depth, sync.OnceFunc(func() { n.Unlock() }), nil
+}
+
+// Unlock decrements the lock count of n, like the M command `LOCK -node`, releasing the lock when the count reaches zero.
+func (n *Node) Unlock() error {
+	if n.conn.probing {
+		return n.conn.probeWrite()
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	ret := C.ydb_lock_decr_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)))
+	if ret == C.YDB_OK {
+		addLockCount(n, -1)
+	}
+	return n.conn.Error(ret)
+}
+
+// DumpLocks writes to w a human-readable list of the locks held by this process, one per line, giving each locked node
+// and its lock count (the number of times it has been locked with Lock() but not yet unlocked), in order of node.
+// For example:
+//
+//	^account("1") count=2
+//
+// This is intended for debugging lock leaks. It writes "no locks held" if there are none. Since locks belong to the
+// process, the list includes locks made using any Conn, but only those made through this package.
+func (conn *Conn) DumpLocks(w io.Writer) error {
+	lockCounts.Lock()
+	counts := maps.Clone(lockCounts.m)
+	lockCounts.Unlock()
+	if len(counts) == 0 {
+		_, err := io.WriteString(w, "no locks held\n")
+		return err
+	}
+	for _, key := range slices.Sorted(maps.Keys(counts)) {
+		if _, err := fmt.Fprintf(w, "%s count=%d\n", key, counts[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
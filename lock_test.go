@@ -0,0 +1,166 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// holdLock makes a separate process acquire a lock on the node given by varname and subscripts, since YottaDB locks
+// are owned by the process. It returns a function that makes the process release the lock and exit.
+func holdLock(t *testing.T, varname string, subscripts ...string) (release func()) {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperHoldLock$")
+	cmd.Env = append(os.Environ(), "YDBGO_HOLDLOCK="+strings.Join(append([]string{varname}, subscripts...), ","))
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	line, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil || line != "locked\n" {
+		t.Fatalf("lock holding process failed: %q, %v", line, err)
+	}
+	return sync.OnceFunc(func() {
+		stdin.Close()
+		cmd.Wait()
+	})
+}
+
+// TestHelperHoldLock is not a real test: it is run as a subprocess by holdLock() to hold a lock until stdin is closed.
+func TestHelperHoldLock(t *testing.T) {
+	spec := os.Getenv("YDBGO_HOLDLOCK")
+	if spec == "" {
+		t.Skip("only run as a subprocess by holdLock()")
+	}
+	names := strings.Split(spec, ",")
+	n := NewConn().Node(names[0], names[1:]...)
+	if err := n.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout.WriteString("locked\n")
+	bufio.NewReader(os.Stdin).ReadString('\n')
+	n.Unlock()
+	os.Exit(0)
+}
+
+// Test that Lock honours the connection's default timeout and that an explicit timeout overrides it.
+func TestLockTimeout(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^locktimeout")
+	release := holdLock(t, "^locktimeout")
+	defer release()
+
+	t.Run("Default", func(t *testing.T) {
+		conn.SetDefaultTimeout(50 * time.Millisecond)
+		start := time.Now()
+		err := n.Lock()
+		elapsed := time.Since(start)
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Fatalf("got error %v, want %v", err, ErrLockTimeout)
+		}
+		if elapsed < 50*time.Millisecond {
+			t.Errorf("got timeout after %v, want at least %v", elapsed, 50*time.Millisecond)
+		}
+	})
+	t.Run("Override", func(t *testing.T) {
+		conn.SetDefaultTimeout(time.Hour)
+		start := time.Now()
+		err := n.Lock(10 * time.Millisecond)
+		elapsed := time.Since(start)
+		if !errors.Is(err, ErrLockTimeout) {
+			t.Fatalf("got error %v, want %v", err, ErrLockTimeout)
+		}
+		if elapsed > 10*time.Second {
+			t.Errorf("got timeout after %v, want about %v", elapsed, 10*time.Millisecond)
+		}
+	})
+	t.Run("Acquire", func(t *testing.T) {
+		conn.SetDefaultTimeout(0)
+		release()
+		if err := n.Lock(); err != nil {
+			t.Fatal(err)
+		}
+		if err := n.Unlock(); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+// Test that LockNested reports the lock depth as a node is locked and released repeatedly.
+func TestLockNested(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^locknested", "a")
+	depth1, release1, err := n.LockNested(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	depth2, release2, _ := n.LockNested(0)
+	if depth1 != 1 || depth2 != 2 {
+		t.Errorf("got depths %d, %d, want %d, %d", depth1, depth2, 1, 2)
+	}
+	release2()
+	release2() // no further effect
+	depth3, release3, _ := NewConn().Node("^locknested", "a").LockNested(0)
+	if depth3 != 2 {
+		t.Errorf("got depth %d after releasing once, want %d", depth3, 2)
+	}
+	release3()
+	release1()
+	depth, release, _ := n.LockNested(0)
+	if depth != 1 {
+		t.Errorf("got depth %d after releasing all, want %d", depth, 1)
+	}
+	release()
+	if count := addLockCount(n, 0); count != 0 {
+		t.Errorf("got lock count %d, want %d", count, 0)
+	}
+}
+
+// Test that DumpLocks lists the locks held with their nesting counts.
+func TestDumpLocks(t *testing.T) {
+	conn := NewConn()
+	a, b := conn.Node("^dumplocks", "a"), conn.Node("^dumplocks", "b")
+	for _, n := range []*Node{a, a, b, a} {
+		if err := n.Lock(0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var buf strings.Builder
+	if err := conn.DumpLocks(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if want := "^dumplocks(\"a\") count=3\n^dumplocks(\"b\") count=1\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("got dump:\n%s\nwant it to contain:\n%s", buf.String(), want)
+	}
+	for _, n := range []*Node{a, a, b, a} {
+		n.Unlock()
+	}
+	buf.Reset()
+	conn.DumpLocks(&buf)
+	if strings.Contains(buf.String(), "^dumplocks") {
+		t.Errorf("got dump:\n%s\nafter unlocking all", buf.String())
+	}
+}
@@ -0,0 +1,79 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Metadata stored alongside a node's data.
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// MetaSubscript is the reserved subscript under which a node's metadata is stored, so that metadata key k of node n
+// is stored at n.Child(MetaSubscript, k). Applications must not use it as an ordinary subscript.
+// It begins with a NUL byte so it collates after all numeric subscripts and before any printable string subscript.
+// Use ChildrenSkipMeta() to iterate over a node's children without its metadata. Note that Tree(), NodeNext() and the
+// collection functions do not treat it specially.
+const MetaSubscript = "\x00meta"
+
+// SetMeta sets metadata key of n to val, for example a version or ownership tag.
+func (n *Node) SetMeta(key, val string) error {
+	return n.Child(MetaSubscript, key).Set(val)
+}
+
+// GetMeta returns the value of metadata key of n.
+// If it does not exist it returns the YottaDB error YDB_ERR_LVUNDEF or YDB_ERR_GVUNDEF as Get() does.
+func (n *Node) GetMeta(key string) (string, error) {
+	return n.Child(MetaSubscript, key).Get()
+}
+
+// versionKey is the metadata key (see SetMeta()) where UpdateVersioned() keeps the version of a node's value.
+const versionKey = "version"
+
+// ErrVersionConflict is returned by UpdateVersioned() when the node's version is not the version expected.
+var ErrVersionConflict = errors.New("YDB: version conflict")
+
+// UpdateVersioned sets n to newVal only if the version of its value is still expectedVersion, for optimistic concurrency
+// control: read a value and its version with Get() and GetMeta("version"), compute a new value, and update it only if
+// no one else updated it meanwhile. The version is kept as metadata key "version" of n (see SetMeta()) and is 0 if
+// it has never been set. On success UpdateVersioned returns the new version, expectedVersion+1. If the version has
+// changed, it returns the current version and an error wrapping ErrVersionConflict, after which the caller may re-read
+// the value and try again. The check and both updates are made in one transaction, so they are atomic, but only
+// against other updates that also use UpdateVersioned().
+func (n *Node) UpdateVersioned(expectedVersion int, newVal string) (int, error) {
+	version := n.Child(MetaSubscript, versionKey)
+	var current int
+	err := n.conn.Transaction(func(conn *Conn) error {
+		s, err := version.Get("0")
+		if err != nil {
+			return err
+		}
+		current, err = strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("YDB: invalid version %q of %s: %w", s, n, err)
+		}
+		if current != expectedVersion {
+			return fmt.Errorf("%w: %s is at version %d, not %d", ErrVersionConflict, n, current, expectedVersion)
+		}
+		if err := n.Set(newVal); err != nil {
+			return err
+		}
+		current++
+		return version.Set(strconv.Itoa(current))
+	})
+	if err != nil && !errors.Is(err, ErrVersionConflict) {
+		return 0, err
+	}
+	return current, err
+}
@@ -0,0 +1,108 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"iter"
+	"slices"
+	"sync"
+	"testing"
+)
+
+// Test storing metadata and skipping it during child iteration.
+func TestMeta(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^meta")
+	n.DeleteTree()
+	n.Child("a").Set("1")
+	n.Child("b").Set("2")
+	if err := n.SetMeta("version", "3"); err != nil {
+		t.Fatal(err)
+	}
+	n.SetMeta("owner", "me")
+
+	got, err := n.GetMeta("version")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "3" {
+		t.Errorf("got %s, want %s", got, "3")
+	}
+	if _, err := n.GetMeta("missing"); !isUndef(err) {
+		t.Errorf("got error %v, want undefined", err)
+	}
+
+	children := func(seq iter.Seq[*Node]) []string {
+		var subs []string
+		for child := range seq {
+			subs = append(subs, child.subscripts()[0])
+		}
+		return subs
+	}
+	want := []string{MetaSubscript, "a", "b"}
+	if got := children(n.Children()); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	want = []string{"a", "b"}
+	if got := children(n.ChildrenSkipMeta()); !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+// Test that concurrent UpdateVersioned() calls from the same version let exactly one update win.
+func TestUpdateVersioned(t *testing.T) {
+	n := NewConn().Node("^versioned")
+	n.DeleteTree()
+	if version, err := n.UpdateVersioned(0, "first"); err != nil || version != 1 {
+		t.Fatalf("got version %d, error %v; want 1, nil", version, err)
+	}
+
+	const updaters = 8
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var winners []string
+	for i := range updaters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			val := string(rune('a' + i))
+			version, err := NewConn().Node("^versioned").UpdateVersioned(1, val)
+			switch {
+			case err == nil && version == 2:
+				mu.Lock()
+				winners = append(winners, val)
+				mu.Unlock()
+			case !errors.Is(err, ErrVersionConflict) || version != 2:
+				t.Errorf("got version %d, error %v; want a conflict at version 2", version, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if len(winners) != 1 {
+		t.Fatalf("got %d winning updates, want 1", len(winners))
+	}
+	if got, _ := n.Get(); got != winners[0] {
+		t.Errorf("got value %q, want the winner's %q", got, winners[0])
+	}
+	if got, _ := n.GetMeta(versionKey); got != "2" {
+		t.Errorf("got version %s, want 2", got)
+	}
+	// A stale update is rejected without changing the value
+	if version, err := n.UpdateVersioned(1, "stale"); !errors.Is(err, ErrVersionConflict) || version != 2 {
+		t.Errorf("got version %d, error %v; want a conflict at version 2", version, err)
+	}
+	if got, _ := n.Get(); got != winners[0] {
+		t.Errorf("got value %q after a stale update, want %q", got, winners[0])
+	}
+}
@@ -0,0 +1,118 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Optional metrics about the wrapper's use of YottaDB.
+
+package yottadb
+
+import (
+	"expvar"
+	"strconv"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds of the buckets of each latency Histogram.
+var LatencyBuckets = [...]time.Duration{
+	time.Microsecond, 10 * time.Microsecond, 100 * time.Microsecond,
+	time.Millisecond, 10 * time.Millisecond, 100 * time.Millisecond, time.Second,
+}
+
+// Histogram counts durations in LatencyBuckets. It may be updated and read concurrently.
+type Histogram struct {
+	buckets [len(LatencyBuckets) + 1]atomic.Uint64 // the last bucket counts durations above the largest bound
+	sum     atomic.Int64                           // total of all durations observed, in nanoseconds
+}
+
+// Observe adds duration d to the histogram.
+func (h *Histogram) Observe(d time.Duration) {
+	i := 0
+	for i < len(LatencyBuckets) && d > LatencyBuckets[i] {
+		i++
+	}
+	h.buckets[i].Add(1)
+	h.sum.Add(int64(d))
+}
+
+// Count returns the number of durations observed.
+func (h *Histogram) Count() uint64 {
+	var count uint64
+	for i := range h.buckets {
+		count += h.buckets[i].Load()
+	}
+	return count
+}
+
+// Cumulative returns the histogram in the form taken by Prometheus' `prometheus.MustNewConstHistogram()`:
+// the number of durations observed, their sum in seconds, and the cumulative count of durations less than or equal to
+// each upper bound of LatencyBuckets, keyed by that bound in seconds.
+func (h *Histogram) Cumulative() (count uint64, sum float64, buckets map[float64]uint64) {
+	buckets = make(map[float64]uint64, len(LatencyBuckets))
+	for i, bound := range LatencyBuckets {
+		count += h.buckets[i].Load()
+		buckets[bound.Seconds()] = count
+	}
+	count += h.buckets[len(LatencyBuckets)].Load()
+	return count, time.Duration(h.sum.Load()).Seconds(), buckets
+}
+
+// Metrics holds counters and histograms describing this process's use of YottaDB through the wrapper.
+// They are only updated while metrics are enabled with EnableMetrics(), to avoid their overhead otherwise.
+// To export them to Prometheus, write a `prometheus.Collector` that reads them using Load() and Cumulative().
+type Metrics struct {
+	Operations    Histogram     // latency of each database operation: get, set, data, delete, increment, and traversal
+	LockWaits     Histogram     // time taken by each Lock() to acquire its lock or time out
+	Transactions  atomic.Uint64 // number of transactions started with Transaction() or Snapshot()
+	Restarts      atomic.Uint64 // number of times a transaction was restarted
+	Reallocations atomic.Uint64 // number of times a buffer was enlarged to fit a longer subscript
+}
+
+var metrics Metrics
+var metricsEnabled atomic.Bool
+
+// EnableMetrics enables or disables updating of the metrics returned by GetMetrics(). Metrics are disabled by default.
+func EnableMetrics(enable bool) {
+	metricsEnabled.Store(enable)
+}
+
+// GetMetrics returns the metrics for this process. The values continue to be updated while metrics are enabled.
+func GetMetrics() *Metrics {
+	return &metrics
+}
+
+// Publish publishes the metrics with the expvar package under the given name, so that they are served as JSON at
+// /debug/vars by any HTTP server that uses http.DefaultServeMux. Like expvar.Publish(), it panics if name is already used.
+func (m *Metrics) Publish(name string) {
+	expvar.Publish(name, expvar.Func(func() any {
+		histogram := func(h *Histogram) map[string]any {
+			count, sum, buckets := h.Cumulative()
+			les := make(map[string]uint64, len(buckets)) // JSON keys must be strings
+			for bound, n := range buckets {
+				les[strconv.FormatFloat(bound, 'g', -1, 64)] = n
+			}
+			return map[string]any{"count": count, "sum": sum, "buckets": les}
+		}
+		return map[string]any{
+			"operations":    histogram(&m.Operations),
+			"lockWaits":     histogram(&m.LockWaits),
+			"transactions":  m.Transactions.Load(),
+			"restarts":      m.Restarts.Load(),
+			"reallocations": m.Reallocations.Load(),
+		}
+	}))
+}
+
+// observeOperation records the latency of a database operation that started at start.
+// Call it as `defer observeOperation(time.Now())` only if metricsEnabled is true.
+func observeOperation(start time.Time) {
+	metrics.Operations.Observe(time.Since(start))
+}
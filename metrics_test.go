@@ -0,0 +1,83 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"encoding/json"
+	"expvar"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Test that metrics advance when enabled and not otherwise.
+func TestMetrics(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^metrics")
+	m := GetMetrics()
+	ops, txns, restarts, reallocs, locks := m.Operations.Count(), m.Transactions.Load(), m.Restarts.Load(), m.Reallocations.Load(), m.LockWaits.Count()
+
+	n.Set("untracked")
+	if got := m.Operations.Count(); got != ops {
+		t.Errorf("got %d operations while disabled, want %d", got, ops)
+	}
+
+	EnableMetrics(true)
+	defer EnableMetrics(false)
+	n.Set("1")
+	n.Get()
+	n.Data()
+	n.Child(strings.Repeat("x", 2*mutableSpare)).Set("long")
+	tries := 0
+	conn.Transaction(func(conn *Conn) error {
+		tries++
+		if tries == 1 {
+			return ErrRestart
+		}
+		return nil
+	})
+	for range n.Children() {
+	}
+	n.Lock(0)
+	n.Unlock()
+
+	if got, want := m.Operations.Count()-ops, uint64(6); got < want {
+		t.Errorf("got %d operations, want at least %d", got, want)
+	}
+	if got := m.Transactions.Load() - txns; got != 1 {
+		t.Errorf("got %d transactions, want %d", got, 1)
+	}
+	if got := m.Restarts.Load() - restarts; got != 1 {
+		t.Errorf("got %d restarts, want %d", got, 1)
+	}
+	if got := m.Reallocations.Load() - reallocs; got != 1 {
+		t.Errorf("got %d reallocations, want %d", got, 1)
+	}
+	if got := m.LockWaits.Count() - locks; got != 1 {
+		t.Errorf("got %d lock waits, want %d", got, 1)
+	}
+
+	count, sum, buckets := m.Operations.Cumulative()
+	if count != m.Operations.Count() || sum <= 0 || buckets[time.Second.Seconds()] > count {
+		t.Errorf("got inconsistent histogram count %d, sum %v, buckets %v", count, sum, buckets)
+	}
+
+	m.Publish("ydbmetrics")
+	var vars map[string]any
+	if err := json.Unmarshal([]byte(expvar.Get("ydbmetrics").String()), &vars); err != nil {
+		t.Fatal(err)
+	}
+	if vars["transactions"] == nil || vars["operations"] == nil {
+		t.Errorf("got expvar %v, missing metrics", vars)
+	}
+}
@@ -16,29 +16,21 @@ package yottadb
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
 	"unsafe"
 )
 
 /* #include "libyottadb.h"
-// Create a thread-specific 'connection' object for calling the YottaDB API.
-typedef struct conn {
-	uint64_t tptoken;	// place to store tptoken for thread-safe ydb_*_st() function calls
-	ydb_buffer_t errstr;	// space for YottaDB to return an error string
-	ydb_buffer_t value;	// temporary space to store in or out value for get/set
-} conn;
-
-// Create a representation of a database node, including a cache of its subscript strings for fast calls to the YottaDB API.
-typedef struct node {
-	conn *conn;
-	int len;		// number of buffers[] allocated to store subscripts/strings
-	int datasize;		// length of string `data` field (all strings and subscripts concatenated)
-	int mutable;		// whether the node is mutable (these are only emitted by node iterators)
-	ydb_buffer_t buffers[1];	// first of an array of buffers (typically varname)
-	ydb_buffer_t buffersn[];	// rest of array
-	// char *data;		// stored after `buffers` (however large they are), which point into this data
-} node;
+#include "yottadb.h"
 */
 import "C"
 
@@ -49,7 +41,26 @@ const initial_value_size = 1024 // Initial size of value storage in each node
 // Wrap C.conn in a Go struct so we can add methods to it.
 type Conn struct {
 	// Pointer to C.conn rather than the item itself so we can malloc it and point to it from C without Go moving it.
-	c *C.conn
+	c             *C.conn
+	timeout       time.Duration     // default timeout for operations that wait, such as Lock(); zero means wait indefinitely
+	tpDepth       int               // number of nested Transaction() calls currently running on this connection
+	undefMode     UndefMode         // what Get() returns for a node without a value
+	skipTP        bool              // whether Transaction() first tries running fn outside TP in case it is read-only
+	probing       bool              // whether Transaction() is currently running fn outside TP to see if it is read-only
+	wrote         bool              // whether a write was attempted while probing
+	txStats       TxStats           // statistics of the most recently completed transaction, returned by TxStats()
+	restartReason RestartReason     // why the current transaction callback is asking YottaDB to restart, if it is
+	tx            *tpInfo           // the transaction (or read-only probe) that conn is currently running, if any
+	codec         Codec             // format used by SetValue() and GetValue(), registered with SetCodec()
+	lastErr       error             // most recent error reported by YottaDB, returned by LastError()
+	onCommit      func([]*Node)     // handler called with the nodes updated by each committed transaction; see OnCommit()
+	onRestart     func(int, string) // handler called before each transaction restart; see OnRestart()
+	verifyReads   bool              // whether to check that transactions read their own writes; see SetVerifyReads()
+	recorder      io.Writer         // where to record database updates; see StartRecording()
+	recordErr     error             // first error writing to recorder
+	retryPolicy   *RetryPolicy      // how to retry operations that fail with transient errors, or nil; see WithRetry()
+	scratch       *Node             // reusable mutable node for Conn.Set() and Conn.Get()
+	scratchBufs   int               // number of ydb_buffer_t's allocated in scratch
 }
 
 // Create a new connection for the current thread.
@@ -68,8 +79,13 @@ func NewConn() *Conn {
 	conn.c.value.buf_addr = (*C.char)(C.malloc(initialSpace))
 	conn.c.value.len_alloc = C.uint(initialSpace)
 	conn.c.value.len_used = 0
+	// Space for subscripts returned by node iteration is allocated on first use
+	C.memset(unsafe.Pointer(&conn.c.subs[0]), 0, C.sizeof_ydb_buffer_t*C.YDB_MAX_SUBS)
 
 	runtime.AddCleanup(&conn, func(cn *C.conn) {
+		for i := range cn.subs {
+			C.free(unsafe.Pointer(cn.subs[i].buf_addr))
+		}
 		C.free(unsafe.Pointer(cn.value.buf_addr))
 		C.free(unsafe.Pointer(cn.errstr.buf_addr))
 		C.free(unsafe.Pointer(cn))
@@ -77,6 +93,22 @@ func NewConn() *Conn {
 	return &conn
 }
 
+// UndefMode determines what Get() returns when reading a node that has no value.
+type UndefMode int
+
+const (
+	// UndefError makes Get() return the YottaDB error YDB_ERR_GVUNDEF or YDB_ERR_LVUNDEF. This is the default.
+	UndefError UndefMode = iota
+	// UndefEmptyString makes Get() return "" and no error, like reading an undefined node in M with VIEW "NOUNDEF".
+	UndefEmptyString
+)
+
+// SetUndefMode sets what Get() returns on conn when reading a node that has no value, unless Get() is given a default.
+// Functions that must distinguish nodes without a value, such as GetSlice(), are not affected.
+func (conn *Conn) SetUndefMode(mode UndefMode) {
+	conn.undefMode = mode
+}
+
 // Return previous error message as an `error` type or nil if there was no error
 func (conn *Conn) Error(code C.int) error {
 	if code == C.YDB_OK {
@@ -84,7 +116,28 @@ func (conn *Conn) Error(code C.int) error {
 	}
 	// Take a copy of errstr as a Go String
 	msg := C.GoStringN(conn.c.errstr.buf_addr, C.int(conn.c.errstr.len_used))
-	return Error(int(code), msg)
+	conn.lastErr = Error(int(code), msg)
+	return conn.lastErr
+}
+
+// LastError returns the most recent error that YottaDB reported to an operation on conn, as a *YDBError with its code
+// and message, or nil if there has been none. Successful operations do not clear it. This gives access to the details
+// of an error after recovering from the panic of a Must function, or after a function that reports only failure.
+// It reflects only operations on conn, not those of other connections.
+func (conn *Conn) LastError() error {
+	return conn.lastErr
+}
+
+// Exec runs ops in sequence on conn, stopping at the first that returns an error and returning that error wrapped with
+// the index of the failed op, counting from 0. This avoids checking the error of each step of a script-like sequence.
+// The ops are not run as a transaction unless Exec is itself called within Transaction().
+func (conn *Conn) Exec(ops ...func(*Conn) error) error {
+	for i, op := range ops {
+		if err := op(conn); err != nil {
+			return fmt.Errorf("YDB: Exec op %d failed: %w", i, err)
+		}
+	}
+	return nil
 }
 
 // Node is an object containing strings that represents a YottaDB node, supporting fast calls to the YottaDB C API.
@@ -96,13 +149,149 @@ func (conn *Conn) Error(code C.int) error {
 // Wraps C.node in a Go struct so we can add methods to it.
 type Node struct {
 	// Pointer to C.node rather than the item itself so we can point to it from C without Go moving it.
-	n    *C.node
-	conn *Conn // Node.conn points to the Go conn; Node.n.conn will point directly to the C.conn
+	n         *C.node
+	conn      *Conn                  // Node.conn points to the Go conn; Node.n.conn will point directly to the C.conn
+	str       atomic.Pointer[string] // cached result of String(); only used for immutable nodes since mutable nodes may change
+	collation int                    // collation sequence id of the node's variable (see NodeOptions)
+	cache     *CachedNode            // cache used by CachedGet(); nil until first used
+}
+
+// ErrInvalidNode is returned by NodeOpts() when validation is requested and the varname or subscripts are invalid.
+var ErrInvalidNode = errors.New("YDB: invalid node")
+
+// NodeOptions specifies a node to create with NodeOpts() and how to create it.
+type NodeOptions struct {
+	Varname    string
+	Subscripts []string
+	// Validate checks that Varname is a valid M variable name (optionally prefixed by "^" for a global) or
+	// intrinsic special variable (prefixed by "$"), and that there are at most YDB_MAX_SUBS subscripts of at most
+	// YDB_MAX_STR bytes each. Otherwise invalid nodes are only reported when a database operation uses them.
+	// Variable names may begin with "%", as in the local %util or the global ^%x, as M allows.
+	// M's naked references, such as ^(sub), which reuse the global last referenced, are deliberately not supported:
+	// every Node names its variable explicitly, so Validate rejects a varname of just "^".
+	Validate bool
+	// Collation is the id of the alternative collation sequence that the database uses for the variable, as
+	// configured for globals with GDE, or 0 (the default) for YottaDB's standard collation. Go code that compares
+	// subscripts on behalf of the node, such as ChildrenInRange(), uses the comparison registered for the id with
+	// RegisterCollation(). Nodes derived from the node, such as by Child(), share its collation.
+	Collation int
+	// Mutable creates a mutable node with spare space after its last subscript, like the nodes yielded by iterators.
+	// Mutable nodes do not cache String() and must not be shared with other goroutines.
+	Mutable bool
+}
+
+// NodeOpts creates a Node as specified by opts. It returns an error wrapping ErrInvalidNode if opts.Validate is set and
+// the node is invalid, or if opts.Collation has not been registered with RegisterCollation().
+func (conn *Conn) NodeOpts(opts NodeOptions) (*Node, error) {
+	if opts.Validate {
+		if err := validateNode(opts.Varname, opts.Subscripts); err != nil {
+			return nil, err
+		}
+	}
+	if opts.Collation != 0 && collationFunc(opts.Collation) == nil {
+		return nil, fmt.Errorf("%w: collation %d is not registered", ErrInvalidNode, opts.Collation)
+	}
+	n := &Node{conn: conn, collation: opts.Collation}
+	spare := 0
+	if opts.Mutable {
+		spare = mutableSpare
+	}
+	n.alloc(opts.Varname, opts.Subscripts, spare)
+	return n, nil
 }
 
 // Create a `Node` instance that represents a database node with class methods for fast calls to YottaDB.
 // The strings and array are stored in C-allocated space to give Node methods fast access to YottaDB API functions.
-func (conn *Conn) Node(varname string, subscripts ...string) (n *Node) {
+// Use NodeOpts() for more control over how the node is created.
+func (conn *Conn) Node(varname string, subscripts ...string) *Node {
+	n, _ := conn.NodeOpts(NodeOptions{Varname: varname, Subscripts: subscripts}) // cannot fail with these options
+	return n
+}
+
+// validateNode returns an error wrapping ErrInvalidNode unless varname and subscripts specify a valid node.
+func validateNode(varname string, subscripts []string) error {
+	if varname == "^" {
+		return fmt.Errorf("%w: naked references are not supported; name the global explicitly", ErrInvalidNode)
+	}
+	name := strings.TrimPrefix(varname, "^")
+	if isv, ok := strings.CutPrefix(varname, "$"); ok {
+		name = isv
+	}
+	valid := len(name) > 0 && len(name) <= C.YDB_MAX_IDENT
+	for i, c := range []byte(name) {
+		alpha := c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z'
+		if !alpha && !(i == 0 && c == '%' && varname[0] != '$') && !(i > 0 && c >= '0' && c <= '9') {
+			valid = false
+		}
+	}
+	if !valid {
+		return fmt.Errorf("%w: invalid variable name %q", ErrInvalidNode, varname)
+	}
+	if len(subscripts) > C.YDB_MAX_SUBS {
+		return fmt.Errorf("%w: %d subscripts exceeds the maximum of %d", ErrInvalidNode, len(subscripts), C.YDB_MAX_SUBS)
+	}
+	for _, sub := range subscripts {
+		if len(sub) > C.YDB_MAX_STR {
+			return fmt.Errorf("%w: subscript of %d bytes exceeds the maximum of %d", ErrInvalidNode, len(sub), C.YDB_MAX_STR)
+		}
+	}
+	return nil
+}
+
+// maxKeySize is the largest database key, in bytes, that YottaDB allows a region to be configured for. A region's
+// configured key size may be smaller, in which case YottaDB reports YDB_ERR_GVSUBOFLOW for a key that is too long.
+const maxKeySize = 1019
+
+// Validate checks the varname and subscripts of n against the constraints of YottaDB, so that callers can check
+// nodes built from program data before using them. It returns an error wrapping ErrInvalidNode that describes the
+// first violation found, or nil if there is none. The constraints checked are:
+//   - the varname is a valid local, global or intrinsic special variable name;
+//   - there are no more than YDB_MAX_SUBS subscripts, each no longer than YDB_MAX_STR;
+//   - the key of a global node fits within the largest key size a region may have (maxKeySize);
+//   - if YottaDB is running in UTF-8 mode ($ZCHSET is "UTF-8"), each subscript is valid UTF-8, since binary
+//     subscripts are only permitted in M mode.
+//
+// The key size is estimated as if every subscript were stored as a string, which slightly overestimates numeric
+// subscripts. Validate cannot determine the key size configured for the region that holds n, which may be smaller.
+func (n *Node) Validate() error {
+	chset, err := n.conn.Node("$ZCHSET").Get()
+	if err != nil {
+		return err
+	}
+	return validateKey(n.varname(), n.subscripts(), chset == "UTF-8")
+}
+
+// validateKey returns an error wrapping ErrInvalidNode unless varname and subscripts specify a valid node whose key
+// fits in a database region. If utf8Mode is true, subscripts must also be valid UTF-8.
+func validateKey(varname string, subscripts []string, utf8Mode bool) error {
+	if err := validateNode(varname, subscripts); err != nil {
+		return err
+	}
+	if strings.HasPrefix(varname, "^") {
+		// The key holds the name and each subscript terminated by a 0 byte, a type byte before each subscript,
+		// a second 0 byte after the last, and an escape byte before each 0 or 1 byte in a subscript.
+		size := len(varname) - 1 + 2
+		for _, sub := range subscripts {
+			size += 2 + len(sub) + strings.Count(sub, "\x00") + strings.Count(sub, "\x01")
+		}
+		if size > maxKeySize {
+			return fmt.Errorf("%w: key of about %d bytes exceeds the maximum of %d", ErrInvalidNode, size, maxKeySize)
+		}
+	}
+	if utf8Mode {
+		for i, sub := range subscripts {
+			if !utf8.ValidString(sub) {
+				return fmt.Errorf("%w: subscript %d (%q) is not valid UTF-8, as required in UTF-8 mode", ErrInvalidNode, i+1, sub)
+			}
+		}
+	}
+	return nil
+}
+
+// alloc allocates and fills in the C.node of n to store varname and subscripts, replacing any C.node it already has.
+// If spare > 0, that many bytes of extra space are reserved after the last subscript and the node is marked mutable,
+// so that setLastSubscript() can replace the last subscript with a longer one without reallocating.
+func (n *Node) alloc(varname string, subscripts []string, spare int) {
 	// Concatenate strings the fastest Go way.
 	// This involves creating an extra copy of subscripts but is probably faster than one C.memcpy call per subscript
 	var joiner bytes.Buffer
@@ -111,25 +300,28 @@ func (conn *Conn) Node(varname string, subscripts ...string) (n *Node) {
 		joiner.WriteString(s)
 	}
 
-	size := C.sizeof_node + C.sizeof_ydb_buffer_t*len(subscripts) + joiner.Len()
+	// C.sizeof_node includes buffers[0] for the varname, so add a buffer for each subscript. yottadb.h checks that
+	// buffersn[] directly follows buffers[0] so that together they form one array of len(subscripts)+1 buffers.
+	size := C.sizeof_node + C.sizeof_ydb_buffer_t*len(subscripts) + joiner.Len() + spare
 	// This initial call must be to calloc() to get initialized (cleared) storage. We cannot allocate it and then
 	// do another call to initialize it as that means uninitialized memory is traversing the cgo boundary which
 	// is what triggers the cgo bug mentioned in the cgo docs (https://golang.org/cmd/cgo/#hdr-Passing_pointers).
 	// TODO: but if we retain calloc, we need to check for memory error because Go doesn't create a wrapper for C.calloc like it does for C.malloc (cf. https://pkg.go.dev/cmd/cgo#hdr-Passing_pointers:~:text=C.malloc%20cannot%20fail)
 	// Alternatively, we could call malloc and then memset to clear just the ydb_buffer_t parts, but test which is faster.
-	var goNode Node
-	n = &goNode
 	n.n = (*C.node)(C.calloc(1, C.size_t(size)))
 	// Queue the cleanup function to free it
 	runtime.AddCleanup(n, func(c_n *C.node) {
 		C.free(unsafe.Pointer(c_n))
 	}, n.n)
 
-	n.conn = conn // point to the Go conn
 	c_n := n.n
-	c_n.conn = (*C.conn)(unsafe.Pointer(conn.c)) // point to the C version of the conn
+	c_n.conn = (*C.conn)(unsafe.Pointer(n.conn.c)) // point to the C version of the conn
 	c_n.len = C.int(len(subscripts) + 1)
+	c_n.datasize = C.int(joiner.Len() + spare)
 	c_n.mutable = 0 // i.e. false
+	if spare > 0 {
+		c_n.mutable = 1
+	}
 
 	dataptr := unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*(len(subscripts)+1))
 	C.memcpy(dataptr, unsafe.Pointer(&joiner.Bytes()[0]), C.size_t(joiner.Len()))
@@ -141,16 +333,161 @@ func (conn *Conn) Node(varname string, subscripts ...string) (n *Node) {
 	buf.len_used, buf.len_alloc = C.uint(len(s)), C.uint(len(s))
 	dataptr = unsafe.Add(dataptr, len(s))
 	for i, s := range subscripts {
-		buf := (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*(i+1)))
+		buf = (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*(i+1)))
+		buf.buf_addr = (*C.char)(dataptr)
+		buf.len_used, buf.len_alloc = C.uint(len(s)), C.uint(len(s))
+		dataptr = unsafe.Add(dataptr, len(s))
+	}
+	buf.len_alloc += C.uint(spare) // the spare space follows the last string
+}
+
+// mutableSpare is the extra space reserved after the last subscript of a mutable node.
+const mutableSpare = 32
+
+// setLastSubscript replaces the last subscript of mutable node n with sub.
+// If sub does not fit in the space available, the node's C.node is reallocated with more space.
+func (n *Node) setLastSubscript(sub string) {
+	c_n := n.n // access C.node from Go node
+	buf := (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*(c_n.len-1)))
+	if len(sub) > int(buf.len_alloc) {
+		subs := n.subscripts()
+		subs[len(subs)-1] = sub
+		n.alloc(n.varname(), subs, mutableSpare)
+		if metricsEnabled.Load() {
+			metrics.Reallocations.Add(1)
+		}
+		return
+	}
+	if len(sub) > 0 {
+		C.memcpy(unsafe.Pointer(buf.buf_addr), unsafe.Pointer(unsafe.StringData(sub)), C.size_t(len(sub)))
+	}
+	buf.len_used = C.uint(len(sub))
+}
+
+// scratchSpare is the extra space reserved for strings in a connection's scratch node, used by Conn.Set() and Conn.Get().
+const scratchSpare = 256
+
+// scratchNode returns conn's reusable mutable node, changed to represent varname and subscripts.
+// Its C.node is only reallocated when they need more buffers or string space than it has.
+func (conn *Conn) scratchNode(varname string, subscripts []string) *Node {
+	size := len(varname)
+	for _, sub := range subscripts {
+		size += len(sub)
+	}
+	n := conn.scratch
+	if n == nil || len(subscripts)+1 > conn.scratchBufs || size > int(n.n.datasize) {
+		n = &Node{conn: conn}
+		n.alloc(varname, subscripts, scratchSpare)
+		conn.scratch, conn.scratchBufs = n, len(subscripts)+1
+		return n
+	}
+	// Refill the existing C.node, whose strings start after all conn.scratchBufs buffers
+	c_n := n.n
+	c_n.len = C.int(len(subscripts) + 1)
+	dataptr := unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*conn.scratchBufs)
+	for i := range len(subscripts) + 1 {
+		s := varname
+		if i > 0 {
+			s = subscripts[i-1]
+		}
+		buf := (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*i))
 		buf.buf_addr = (*C.char)(dataptr)
 		buf.len_used, buf.len_alloc = C.uint(len(s)), C.uint(len(s))
+		if len(s) > 0 {
+			C.memcpy(dataptr, unsafe.Pointer(unsafe.StringData(s)), C.size_t(len(s)))
+		}
 		dataptr = unsafe.Add(dataptr, len(s))
 	}
 	return n
 }
 
+// Set sets the value of the node given by varname and subscripts to val, like Node.Set() but without the caller
+// creating a Node, which is convenient for scripts and tests. It reuses a single node stored in conn, so it does
+// not allocate a new node for each call.
+func (conn *Conn) Set(val string, varname string, subscripts ...string) error {
+	return conn.scratchNode(varname, subscripts).Set(val)
+}
+
+// Get returns the value of the node given by varname and subscripts, like Node.Get() without the caller creating a
+// Node. Like Conn.Set(), it reuses a single node stored in conn.
+func (conn *Conn) Get(varname string, subscripts ...string) (string, error) {
+	return conn.scratchNode(varname, subscripts).Get()
+}
+
+// Copy returns an immutable copy of n. Use it to retain a mutable Node yielded by an iterator, or to share it with another goroutine.
+func (n *Node) Copy() *Node {
+	return n.derive(n.subscripts())
+}
+
+// Child returns a new Node with the given subscripts appended to the subscripts of n.
+func (n *Node) Child(subscripts ...string) *Node {
+	return n.derive(append(n.subscripts(), subscripts...))
+}
+
+// on returns n if it belongs to conn, or else a copy of n that belongs to conn, so that its operations use conn's
+// buffers and form part of any transaction conn is running.
+func (n *Node) on(conn *Conn) *Node {
+	if n.conn == conn {
+		return n
+	}
+	node := &Node{conn: conn, collation: n.collation}
+	node.alloc(n.varname(), n.subscripts(), 0)
+	return node
+}
+
+// derive returns a new immutable Node with the same connection, varname and collation as n but the given subscripts.
+func (n *Node) derive(subscripts []string) *Node {
+	node := &Node{conn: n.conn, collation: n.collation}
+	node.alloc(n.varname(), subscripts, 0)
+	return node
+}
+
+// varname returns a Go copy of the variable name of the node.
+func (n *Node) varname() string {
+	buf := &n.n.buffers[0]
+	return C.GoStringN(buf.buf_addr, C.int(buf.len_used))
+}
+
+// subscripts returns a Go copy of the subscripts of the node (excluding varname).
+func (n *Node) subscripts() []string {
+	c_n := n.n // access C.node from Go node
+	subs := make([]string, c_n.len-1)
+	for i := range subs {
+		buf := (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*(i+1)))
+		subs[i] = C.GoStringN(buf.buf_addr, C.int(buf.len_used))
+	}
+	return subs
+}
+
+// Subscripts returns the subscripts of n, excluding its varname, as a new slice of strings copied from the node's C
+// memory, so that code can manipulate them generically, such as in a transform for CopyToFunc(). Changing the slice
+// or its strings does not affect n.
+func (n *Node) Subscripts() []string {
+	return n.subscripts()
+}
+
+// Key returns a string that identifies the database node that n represents, for use as a map key or to compare nodes.
+// Two nodes have the same Key exactly when they have the same varname and subscripts, even if they belong to different
+// connections. Key is cheaper to build than String().
+func (n *Node) Key() string {
+	var bld strings.Builder
+	bld.WriteString(n.varname())
+	for _, sub := range n.subscripts() {
+		bld.WriteString("\x00")
+		bld.WriteString(strconv.Itoa(len(sub)))
+		bld.WriteString(":")
+		bld.WriteString(sub)
+	}
+	return bld.String()
+}
+
 // Return string representation of this database node in typical YottaDB format: `varname("sub1")("sub2")`.
+// Double quotes within subscripts are doubled, as in M string literals, so that NodeFromString() can parse the result.
+// The result is cached for immutable nodes, atomically so that they may still be shared between goroutines.
 func (n *Node) String() string {
+	if str := n.str.Load(); str != nil {
+		return *str
+	}
 	var bld strings.Builder
 	c_n := n.n // access C.node from Go node
 	for i := range c_n.len {
@@ -158,17 +495,125 @@ func (n *Node) String() string {
 		s := C.GoStringN(buf.buf_addr, C.int(buf.len_used))
 		if i > 0 {
 			bld.WriteString("(\"")
+			s = strings.ReplaceAll(s, `"`, `""`)
 		}
 		bld.WriteString(s)
 		if i > 0 {
 			bld.WriteString("\")")
 		}
 	}
-	return bld.String()
+	str := bld.String()
+	if c_n.mutable == 0 {
+		n.str.Store(&str)
+	}
+	return str
+}
+
+// bufferDump describes one ydb_buffer_t of a node for DumpJSON().
+type bufferDump struct {
+	Value    string `json:"value"`
+	LenUsed  int    `json:"len_used"`
+	LenAlloc int    `json:"len_alloc"`
+}
+
+// nodeDump describes the C representation of a node for DumpJSON().
+type nodeDump struct {
+	Varname    bufferDump   `json:"varname"`
+	Subscripts []bufferDump `json:"subscripts"`
+	Mutable    bool         `json:"mutable"`
+	Datasize   int          `json:"datasize"`
+}
+
+// DumpJSON returns the internal C representation of n as JSON, so that tests and tools can inspect its layout without
+// parsing text: the varname and each subscript as a string with the used and allocated lengths of its ydb_buffer_t,
+// whether the node is mutable, and the size of the space allocated for the strings. For example:
+//
+//	{"varname":{"value":"^x","len_used":2,"len_alloc":2},"subscripts":[{"value":"a","len_used":1,"len_alloc":1}],"mutable":false,"datasize":3}
+//
+// Note that JSON replaces bytes that are not valid UTF-8 with U+FFFD, so use len_used for the true length of such strings.
+func (n *Node) DumpJSON() ([]byte, error) {
+	c_n := n.n // access C.node from Go node
+	dump := nodeDump{Subscripts: []bufferDump{}, Mutable: c_n.mutable != 0, Datasize: int(c_n.datasize)}
+	for i := range c_n.len {
+		buf := (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t*i))
+		b := bufferDump{C.GoStringN(buf.buf_addr, C.int(buf.len_used)), int(buf.len_used), int(buf.len_alloc)}
+		if i == 0 {
+			dump.Varname = b
+		} else {
+			dump.Subscripts = append(dump.Subscripts, b)
+		}
+	}
+	return json.Marshal(dump)
+}
+
+// NodeFromString creates a Node from its string representation as returned by Node.String(), such as
+// `^x("a")("say ""hi""")`. It returns an error wrapping ErrInvalidNode if s is not in that format or does not specify
+// a valid node.
+func (conn *Conn) NodeFromString(s string) (*Node, error) {
+	varname, rest := s, ""
+	if i := strings.IndexByte(s, '('); i >= 0 {
+		varname, rest = s[:i], s[i:]
+	}
+	var subs []string
+	for rest != "" {
+		if !strings.HasPrefix(rest, `("`) {
+			return nil, fmt.Errorf("%w: expected (\" after %q in %q", ErrInvalidNode, s[:len(s)-len(rest)], s)
+		}
+		rest = rest[2:]
+		var sub strings.Builder
+		for {
+			quote := strings.IndexByte(rest, '"')
+			if quote < 0 {
+				return nil, fmt.Errorf("%w: unterminated subscript in %q", ErrInvalidNode, s)
+			}
+			sub.WriteString(rest[:quote])
+			rest = rest[quote+1:]
+			if strings.HasPrefix(rest, `"`) {
+				sub.WriteByte('"') // a doubled quote
+				rest = rest[1:]
+				continue
+			}
+			if !strings.HasPrefix(rest, ")") {
+				return nil, fmt.Errorf("%w: expected ) after subscript in %q", ErrInvalidNode, s)
+			}
+			rest = rest[1:]
+			break
+		}
+		subs = append(subs, sub.String())
+	}
+	return conn.NodeOpts(NodeOptions{Varname: varname, Subscripts: subs, Validate: true})
+}
+
+// StringWithValue returns the string representation of n like String(), followed by "=" and its value in ZWRITE
+// format (see Conn.Str2Zwr()) if it has one, for logging. Values longer than maxLen bytes are truncated to maxLen bytes
+// and followed by "...". If n has no value, or it cannot be read, StringWithValue returns just String().
+func (n *Node) StringWithValue(maxLen int) string {
+	val, err := n.get(nil)
+	if err != nil {
+		return n.String()
+	}
+	truncated := len(val) > maxLen
+	if truncated {
+		val = val[:max(maxLen, 0)]
+	}
+	zwr, err := n.conn.Str2Zwr(val)
+	if err != nil {
+		return n.String()
+	}
+	if truncated {
+		zwr += "..."
+	}
+	return n.String() + "=" + zwr
 }
 
 // Set
 func (n *Node) Set(val string) error {
+	if n.conn.probing {
+		return n.conn.probeWrite()
+	}
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
 	// Create a ydb_buffer_t pointing to go string
 	c_n := n.n // access C.node from Go node
 	conn := c_n.conn
@@ -179,22 +624,56 @@ func (n *Node) Set(val string) error {
 	C.memcpy(unsafe.Pointer(conn.value.buf_addr), unsafe.Pointer(unsafe.StringData(val)), C.size_t(len(val)))
 	conn.value.len_used = C.uint(len(val))
 
-	ret := C.ydb_set_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &conn.value)
-
+	var ret C.int
+	for attempt := 1; ; attempt++ {
+		ret = C.ydb_set_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &conn.value)
+		if !n.conn.retrying(ret, attempt) {
+			break
+		}
+	}
+	if ret == C.YDB_OK {
+		n.conn.recordChange(n)
+		n.conn.recordWrite(n, val)
+		n.conn.recordUpdate(recordSet, n, val)
+	}
 	return n.conn.Error(ret)
 }
 
 // Get the value of a database node.
 // On error return value "" and error
 // If deflt is supplied return string deflt[0] instead of GVUNDEF or LVUNDEF errors.
+// If deflt is not supplied and the connection's UndefMode is UndefEmptyString, return "" instead of those errors.
 func (n *Node) Get(deflt ...string) (string, error) {
-	c_n := n.n // access C.node from Go node
-	conn := c_n.conn
-	err := C.ydb_get_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &conn.value)
+	if len(deflt) == 0 && n.conn.undefMode == UndefEmptyString {
+		return n.get([]string{""})
+	}
+	return n.get(deflt)
+}
+
+// GetOrFunc returns the value of n or, if n has no value (YDB_ERR_GVUNDEF or YDB_ERR_LVUNDEF), the result of calling
+// fn. Unlike a default passed to Get(), fn is only called when it is needed, which suits defaults that are expensive to
+// compute. The default is not stored in n. Other errors are returned without calling fn.
+func (n *Node) GetOrFunc(fn func() (string, error)) (string, error) {
+	val, err := n.get(nil)
+	if isUndef(err) {
+		return fn()
+	}
+	return val, err
+}
+
+// get implements Get() without regard to the connection's UndefMode, so that functions that need to detect nodes
+// without a value can call get(nil).
+func (n *Node) get(deflt []string) (string, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	conn := n.n.conn
+	err := n.getInto(&conn.value)
 	if err == C.YDB_ERR_INVSTRLEN {
 		// TODO: fix the following to realloc
 		panic("YDB: have not yet implemented reallocating conn.value to fit a large returned string")
 	}
+	n.checkRead(err, &conn.value)
 	if len(deflt) > 0 && (err == C.YDB_ERR_GVUNDEF || err == C.YDB_ERR_LVUNDEF) {
 		return deflt[0], n.conn.Error(C.YDB_OK)
 	}
@@ -205,3 +684,298 @@ func (n *Node) Get(deflt ...string) (string, error) {
 	value := C.GoStringN(conn.value.buf_addr, C.int(conn.value.len_used))
 	return value, nil
 }
+
+// getInto reads the value of n into buf with ydb_get_st(), retrying according to the connection's RetryPolicy, and
+// returns YottaDB's return code. All reads of a node's value go through getInto.
+func (n *Node) getInto(buf *C.ydb_buffer_t) C.int {
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	for attempt := 1; ; attempt++ {
+		ret := C.ydb_get_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), buf)
+		if !n.conn.retrying(ret, attempt) {
+			return ret
+		}
+	}
+}
+
+// checkRead passes the value of n read into buf, or the error given by return code ret, to verifyRead() if read
+// verification is enabled and conn is in a transaction.
+func (n *Node) checkRead(ret C.int, buf *C.ydb_buffer_t) {
+	if n.conn.verifyReads && n.conn.tx != nil {
+		var value string
+		if ret == C.YDB_OK {
+			value = C.GoStringN(buf.buf_addr, C.int(buf.len_used))
+		}
+		n.conn.verifyRead(n, value, n.conn.Error(ret))
+	}
+}
+
+// ReadChunks gets the value of n and passes it to fn in successive chunks of at most chunkSize bytes, stopping early if
+// fn returns an error, which ReadChunks then returns; fn is not called for an empty value. The chunks are copied directly from the connection's value buffer
+// into a single reused chunk buffer, so even values approaching YDB_MAX_STR are processed without a Go string of the
+// whole value. Consequently fn must not retain chunk after it returns, and must not use the Conn of n (or nodes created
+// from it), which would overwrite the value being read.
+// If n has no value, ReadChunks returns the YottaDB error YDB_ERR_LVUNDEF or YDB_ERR_GVUNDEF without calling fn.
+func (n *Node) ReadChunks(chunkSize int, fn func(chunk []byte) error) error {
+	if chunkSize < 1 {
+		return fmt.Errorf("YDB: chunk size %d is less than 1", chunkSize)
+	}
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	conn := n.n.conn
+	ret := n.getInto(&conn.value)
+	n.checkRead(ret, &conn.value)
+	if ret != C.YDB_OK {
+		return n.conn.Error(ret)
+	}
+	value := unsafe.Slice((*byte)(unsafe.Pointer(conn.value.buf_addr)), conn.value.len_used)
+	chunk := make([]byte, min(chunkSize, len(value)))
+	for len(value) > 0 {
+		size := copy(chunk, value)
+		if err := fn(chunk[:size]); err != nil {
+			return err
+		}
+		value = value[size:]
+	}
+	return nil
+}
+
+// Increment adds amount to the numeric value of a database node and returns the result, like M's `$INCREMENT()`.
+// An undefined node or a non-numeric value is treated as 0. If amount is "" the node is incremented by 1.
+func (n *Node) Increment(amount string) (string, error) {
+	if err := n.increment(amount); err != nil {
+		return "", err
+	}
+	conn := n.n.conn
+	return C.GoStringN(conn.value.buf_addr, C.int(conn.value.len_used)), nil
+}
+
+// IncrementDiscard adds amount to the numeric value of a database node like Increment(), just as atomically, but does
+// not return the result, which saves copying it into a Go string for fire-and-forget updates such as counters.
+func (n *Node) IncrementDiscard(amount string) error {
+	return n.increment(amount)
+}
+
+// increment implements Increment(), leaving the result in the connection's value buffer.
+func (n *Node) increment(amount string) error {
+	if n.conn.probing {
+		return n.conn.probeWrite()
+	}
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	var incr *C.ydb_buffer_t // nil makes YottaDB increment by 1
+	if amount != "" {
+		incr = &C.ydb_buffer_t{buf_addr: C.CString(amount), len_used: C.uint(len(amount)), len_alloc: C.uint(len(amount))}
+		defer C.free(unsafe.Pointer(incr.buf_addr))
+	}
+	var ret C.int
+	for attempt := 1; ; attempt++ {
+		ret = C.ydb_incr_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), incr, &conn.value)
+		if !n.conn.retrying(ret, attempt) {
+			break
+		}
+	}
+	if ret != C.YDB_OK {
+		return n.conn.Error(ret)
+	}
+	n.conn.recordChange(n)
+	if n.conn.verifyReads || n.conn.recorder != nil {
+		val := C.GoStringN(conn.value.buf_addr, C.int(conn.value.len_used))
+		n.conn.recordWrite(n, val)
+		n.conn.recordUpdate(recordSet, n, val)
+	}
+	return nil
+}
+
+// MustSet is like Set() but panics on error, with n included in the error.
+// It is intended for scripts and tests where any error is fatal.
+func (n *Node) MustSet(val string) {
+	if err := n.Set(val); err != nil {
+		panic(fmt.Errorf("%w: setting %v", err, n))
+	}
+}
+
+// MustGet is like Get() but panics on error, with n included in the error.
+// It is intended for scripts and tests where any error is fatal.
+func (n *Node) MustGet(deflt ...string) string {
+	val, err := n.Get(deflt...)
+	if err != nil {
+		panic(fmt.Errorf("%w: getting %v", err, n))
+	}
+	return val
+}
+
+// Data returns whether the database node has a value and/or a subtree, as one of the following:
+//   - 0: the node has neither a value nor a subtree
+//   - 1: the node has a value but no subtree
+//   - 10: the node has a subtree but no value
+//   - 11: the node has both a value and a subtree
+func (n *Node) Data() (int, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	var val C.uint
+	var ret C.int
+	for attempt := 1; ; attempt++ {
+		ret = C.ydb_data_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &val)
+		if !n.conn.retrying(ret, attempt) {
+			break
+		}
+	}
+	if ret != C.YDB_OK {
+		return 0, n.conn.Error(ret)
+	}
+	return int(val), nil
+}
+
+// HasChild reports whether n has an immediate child with the given subscript, that is, whether that child has a value
+// or a subtree. It is a convenient membership test.
+func (n *Node) HasChild(subscript string) (bool, error) {
+	data, err := n.Child(subscript).Data()
+	return data != 0, err
+}
+
+// isUndef reports whether err is the YottaDB error for an undefined global or local variable node.
+func isUndef(err error) bool {
+	var ydbErr *YDBError
+	return errors.As(err, &ydbErr) && (ydbErr.code == C.YDB_ERR_GVUNDEF || ydbErr.code == C.YDB_ERR_LVUNDEF)
+}
+
+// Delete removes the value of a database node, leaving any subtree below it intact.
+func (n *Node) Delete() error {
+	return n.delete(C.YDB_DEL_NODE)
+}
+
+// DeleteTree removes the value of a database node and its entire subtree.
+func (n *Node) DeleteTree() error {
+	return n.delete(C.YDB_DEL_TREE)
+}
+
+// KillGlobal deletes the entire global variable name, with or without its leading "^", like the M command `KILL ^name`.
+// It is equivalent to DeleteTree() on the unsubscripted global node, but as a safeguard for administrative scripts it
+// returns an error wrapping ErrInvalidNode, and deletes nothing, unless name is a valid global variable name.
+func (conn *Conn) KillGlobal(name string) error {
+	varname := "^" + strings.TrimPrefix(name, "^")
+	if strings.HasPrefix(varname, "^$") {
+		return fmt.Errorf("%w: %q is not a global variable name", ErrInvalidNode, name)
+	}
+	n, err := conn.NodeOpts(NodeOptions{Varname: varname, Validate: true})
+	if err != nil {
+		return err
+	}
+	return n.DeleteTree()
+}
+
+// delete removes a node using ydb_delete_st() with the given deltype (YDB_DEL_NODE or YDB_DEL_TREE).
+func (n *Node) delete(deltype C.int) error {
+	if n.conn.probing {
+		return n.conn.probeWrite()
+	}
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	var ret C.int
+	for attempt := 1; ; attempt++ {
+		ret = C.ydb_delete_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), deltype)
+		if !n.conn.retrying(ret, attempt) {
+			break
+		}
+	}
+	if ret == C.YDB_OK {
+		n.conn.recordChange(n)
+		n.conn.forgetWrites(n, deltype == C.YDB_DEL_TREE)
+		op := recordDelete
+		if deltype == C.YDB_DEL_TREE {
+			op = recordDeleteTree
+		}
+		n.conn.recordUpdate(op, n, "")
+	}
+	return n.conn.Error(ret)
+}
+
+// ValueLen returns the length in bytes of the value of a database node without copying the value into Go.
+// If the node has no value it returns 0 and the same YottaDB error as Get(): YDB_ERR_GVUNDEF or YDB_ERR_LVUNDEF.
+// Within a transaction with SetVerifyReads() enabled, ValueLen reads the whole value so that the read can be verified.
+func (n *Node) ValueLen() (int, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	if n.conn.verifyReads && n.conn.tx != nil {
+		// The value is needed to verify the read
+		val, err := n.get(nil)
+		return len(val), err
+	}
+	conn := n.n.conn
+	// Get into a zero-length buffer so that YottaDB reports the value's length without copying it
+	var buf C.ydb_buffer_t
+	buf.buf_addr = conn.value.buf_addr
+	ret := n.getInto(&buf)
+	if ret == C.YDB_ERR_INVSTRLEN || ret == C.YDB_OK {
+		return int(buf.len_used), nil
+	}
+	return 0, n.conn.Error(ret)
+}
+
+// GetCapped gets at most maxLen bytes of the value of n, and reports whether the value was truncated to fit.
+// It is useful to preview a value that may be large without copying all of it into Go.
+// If n has no value, GetCapped returns the YottaDB error YDB_ERR_LVUNDEF or YDB_ERR_GVUNDEF.
+func (n *Node) GetCapped(maxLen int) (value string, truncated bool, err error) {
+	if maxLen < 0 {
+		return "", false, fmt.Errorf("YDB: maximum length %d is less than 0", maxLen)
+	}
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	conn := n.n.conn
+	// Read into a buffer bounded to maxLen; if the value is longer, YottaDB reports its full length in len_used
+	var capped C.ydb_buffer_t
+	capped.buf_addr = conn.value.buf_addr
+	capped.len_alloc = C.uint(min(maxLen, int(conn.value.len_alloc)))
+	ret := n.getInto(&capped)
+	if ret != C.YDB_ERR_INVSTRLEN {
+		n.checkRead(ret, &capped)
+	}
+	if ret == C.YDB_OK {
+		return C.GoStringN(capped.buf_addr, C.int(capped.len_used)), false, nil
+	}
+	if ret != C.YDB_ERR_INVSTRLEN {
+		return "", false, n.conn.Error(ret)
+	}
+	// YottaDB does not fill a buffer that is too short, so read the whole value into the C buffer and copy only its start
+	ret = n.getInto(&conn.value)
+	n.checkRead(ret, &conn.value)
+	if ret != C.YDB_OK {
+		return "", false, n.conn.Error(ret)
+	}
+	return C.GoStringN(conn.value.buf_addr, C.int(min(maxLen, int(conn.value.len_used)))), conn.value.len_used > C.uint(maxLen), nil
+}
+
+// ValueEquals reports whether the value of n equals b, such as to detect whether a value has changed. It compares the
+// value in the connection's C buffer with b directly, without creating a Go string of the value, and compares the bytes
+// only if the lengths match. If n has no value, ValueEquals returns false and no error.
+func (n *Node) ValueEquals(b []byte) (bool, error) {
+	if metricsEnabled.Load() {
+		defer observeOperation(time.Now())
+	}
+	conn := n.n.conn
+	ret := n.getInto(&conn.value)
+	n.checkRead(ret, &conn.value)
+	if ret == C.YDB_ERR_GVUNDEF || ret == C.YDB_ERR_LVUNDEF {
+		return false, nil
+	}
+	if ret != C.YDB_OK {
+		return false, n.conn.Error(ret)
+	}
+	if int(conn.value.len_used) != len(b) {
+		return false, nil
+	}
+	return len(b) == 0 || C.memcmp(unsafe.Pointer(conn.value.buf_addr), unsafe.Pointer(&b[0]), C.size_t(len(b))) == 0, nil
+}
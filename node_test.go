@@ -13,8 +13,14 @@
 package yottadb
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 )
 
@@ -41,6 +47,592 @@ func TestNode(t *testing.T) {
 			t.Errorf("got %s, want %s", ans, expect)
 		}
 	})
+	t.Run("Options", func(t *testing.T) {
+		conn := NewConn()
+		for _, varname := range []string{"var", "^glo", "%pct", "^%x1", "$ZVERSION"} {
+			if _, err := conn.NodeOpts(NodeOptions{Varname: varname, Subscripts: []string{"a"}, Validate: true}); err != nil {
+				t.Errorf("got error %v for valid varname %q", err, varname)
+			}
+		}
+		for _, opts := range []NodeOptions{
+			{Varname: ""},
+			{Varname: "1var"},
+			{Varname: "va-r"},
+			{Varname: "$%x"},
+			{Varname: "%%x"},
+			{Varname: "a%b"},
+			{Varname: "^", Subscripts: []string{"naked"}},
+			{Varname: strings.Repeat("x", 32)},
+			{Varname: "var", Subscripts: make([]string, YDB_MAX_SUBS+1)},
+		} {
+			opts.Validate = true
+			if _, err := conn.NodeOpts(opts); !errors.Is(err, ErrInvalidNode) {
+				t.Errorf("got error %v for %q, want %v", err, opts.Varname, ErrInvalidNode)
+			}
+		}
+		// A %-prefixed local works like any other
+		pct, err := conn.NodeOpts(NodeOptions{Varname: "%util", Subscripts: []string{"a"}, Validate: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pct.Set("percent")
+		if got, err := conn.Node("%util", "a").Get(); err != nil || got != "percent" {
+			t.Errorf("got %q, error %v; want percent", got, err)
+		}
+		if _, err := conn.NodeOpts(NodeOptions{Varname: "1var"}); err != nil {
+			t.Errorf("got error %v without validation", err)
+		}
+
+		reverse := func(a, b string) int { return Collate(b, a) }
+		if _, err := conn.NodeOpts(NodeOptions{Varname: "coll", Collation: 200}); !errors.Is(err, ErrInvalidNode) {
+			t.Errorf("got error %v for unregistered collation, want %v", err, ErrInvalidNode)
+		}
+		RegisterCollation(200, reverse)
+		n, err := conn.NodeOpts(NodeOptions{Varname: "coll", Collation: 200, Mutable: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		n.Child("a").Set("x")
+		n.Child("c").Set("x")
+		if child := n.Child("b"); child.collation != 200 || child.n.mutable != 0 {
+			t.Errorf("got child collation %d and mutable %d, want collation 200 and immutable", child.collation, child.n.mutable)
+		}
+		// In reverse collation "a" follows "c" so this range is empty
+		for child := range n.ChildrenInRange("a", "c") {
+			t.Errorf("got child %v in empty range", child)
+		}
+		if n.n.mutable == 0 {
+			t.Errorf("got immutable node, want mutable")
+		}
+	})
+	t.Run("DumpJSON", func(t *testing.T) {
+		conn := NewConn()
+		got, err := conn.Node("^dump", "ab", "").DumpJSON()
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := `{"varname":{"value":"^dump","len_used":5,"len_alloc":5},"subscripts":[{"value":"ab","len_used":2,"len_alloc":2},` +
+			`{"value":"","len_used":0,"len_alloc":0}],"mutable":false,"datasize":7}`
+		if string(got) != want {
+			t.Errorf("got %s, want %s", got, want)
+		}
+		// The last subscript of a mutable node has spare space
+		var dump struct {
+			Subscripts []struct {
+				LenUsed  int `json:"len_used"`
+				LenAlloc int `json:"len_alloc"`
+			}
+			Mutable  bool
+			Datasize int
+		}
+		mutable := &Node{conn: conn}
+		mutable.alloc("^dump", []string{"xyz"}, mutableSpare)
+		got, _ = mutable.DumpJSON()
+		if err := json.Unmarshal(got, &dump); err != nil {
+			t.Fatal(err)
+		}
+		if !dump.Mutable || dump.Datasize != 8+mutableSpare || dump.Subscripts[0].LenUsed != 3 ||
+			dump.Subscripts[0].LenAlloc != 3+mutableSpare {
+			t.Errorf("got %s for a mutable node", got)
+		}
+	})
+	t.Run("StringMutable", func(t *testing.T) {
+		n := &Node{conn: NewConn()}
+		n.alloc("var", []string{"a"}, mutableSpare)
+		if got := n.String(); got != `var("a")` {
+			t.Errorf("got %s, want %s", got, `var("a")`)
+		}
+		n.setLastSubscript("b")
+		if got := n.String(); got != `var("b")` {
+			t.Errorf("got stale %s, want %s", got, `var("b")`)
+		}
+	})
+	t.Run("StringWithValue", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("strval", "a")
+		n.Delete()
+		for _, test := range []struct{ val, want string }{
+			{"", `strval("a")=""`},
+			{"short", `strval("a")="short"`},
+			{"12", `strval("a")=12`},
+			{"tab\there", `strval("a")="tab"_$C(9)_"here"`},
+			{strings.Repeat("long", 1000), `strval("a")="longlonglo"...`},
+		} {
+			n.Set(test.val)
+			if got := n.StringWithValue(10); got != test.want {
+				t.Errorf("got %s, want %s", got, test.want)
+			}
+		}
+		if got := conn.Node("strval", "absent").StringWithValue(10); got != `strval("absent")` {
+			t.Errorf("got %s, want %s", got, `strval("absent")`)
+		}
+	})
+	t.Run("StringShared", func(t *testing.T) {
+		// An immutable node may be formatted by several goroutines at once; run with -race to check
+		n := NewConn().Node("^shared", "a", "b")
+		var wg sync.WaitGroup
+		for range 4 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if got := n.String(); got != `^shared("a")("b")` {
+					t.Errorf("got %s", got)
+				}
+			}()
+		}
+		wg.Wait()
+	})
+	t.Run("Child", func(t *testing.T) {
+		n := NewConn().Node("var", "sub1").Child("sub2", "sub3")
+		ans := n.String()
+		expect := "var(\"sub1\")(\"sub2\")(\"sub3\")"
+		if ans != expect {
+			t.Errorf("got %s, want %s", ans, expect)
+		}
+	})
+	t.Run("Subscripts", func(t *testing.T) {
+		n := NewConn().Node("^subs", "a", "", "bc")
+		subs := n.Subscripts()
+		if want := []string{"a", "", "bc"}; !slices.Equal(subs, want) {
+			t.Errorf("got %q, want %q", subs, want)
+		}
+		subs[0] = "changed"
+		_ = append(subs[:1], "appended")
+		if got := n.Subscripts(); !slices.Equal(got, []string{"a", "", "bc"}) || n.String() != `^subs("a")("")("bc")` {
+			t.Errorf("got subscripts %q and node %v after changing the slice", got, n)
+		}
+		if subs := NewConn().Node("^subs").Subscripts(); subs == nil || len(subs) != 0 {
+			t.Errorf("got %#v, want an empty slice", subs)
+		}
+	})
+	t.Run("GetOrFunc", func(t *testing.T) {
+		n := NewConn().Node("getorfunc")
+		n.Delete()
+		calls := 0
+		deflt := func() (string, error) { calls++; return "computed", nil }
+		if val, err := n.GetOrFunc(deflt); err != nil || val != "computed" || calls != 1 {
+			t.Errorf("got %q, error %v after %d calls; want computed after 1 call", val, err, calls)
+		}
+		if data, _ := n.Data(); data != 0 {
+			t.Errorf("the default was stored")
+		}
+		n.Set("stored")
+		if val, err := n.GetOrFunc(deflt); err != nil || val != "stored" || calls != 1 {
+			t.Errorf("got %q, error %v after %d calls; want stored without calling fn", val, err, calls)
+		}
+		n.Delete()
+		want := errors.New("failed")
+		if _, err := n.GetOrFunc(func() (string, error) { return "", want }); err != want {
+			t.Errorf("got error %v, want %v", err, want)
+		}
+	})
+	t.Run("GetCapped", func(t *testing.T) {
+		n := NewConn().Node("capped")
+		n.Set("hello")
+		tests := []struct {
+			maxLen    int
+			want      string
+			truncated bool
+		}{{10, "hello", false}, {5, "hello", false}, {3, "hel", true}, {0, "", true}}
+		for _, test := range tests {
+			value, truncated, err := n.GetCapped(test.maxLen)
+			if err != nil || value != test.want || truncated != test.truncated {
+				t.Errorf("GetCapped(%d) got %q, %v, %v; want %q, %v, nil", test.maxLen, value, truncated, err, test.want, test.truncated)
+			}
+		}
+		if _, _, err := n.Child("absent").GetCapped(3); !isUndef(err) {
+			t.Errorf("got error %v, want LVUNDEF", err)
+		}
+		if _, _, err := n.GetCapped(-1); err == nil {
+			t.Errorf("got no error for a negative maximum length")
+		}
+	})
+	t.Run("NodeFromString", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("^quotes", `say "hi"`, `"`, "")
+		if want := `^quotes("say ""hi""")("""")("")`; n.String() != want {
+			t.Errorf("got %s, want %s", n, want)
+		}
+		// Property test: random subscripts heavy in the characters that matter to the format survive a round trip
+		rnd := rand.New(rand.NewPCG(1, 2))
+		const alphabet = `a1"()""`
+		for range 1000 {
+			subs := make([]string, rnd.IntN(5))
+			for i := range subs {
+				b := make([]byte, rnd.IntN(8))
+				for j := range b {
+					b[j] = alphabet[rnd.IntN(len(alphabet))]
+				}
+				subs[i] = string(b)
+			}
+			n := conn.Node("^prop", subs...)
+			parsed, err := conn.NodeFromString(n.String())
+			if err != nil {
+				t.Fatalf("parsing %s: %s", n, err)
+			}
+			if got := parsed.Subscripts(); parsed.varname() != "^prop" || !slices.Equal(got, subs) {
+				t.Fatalf("%s parsed as %s with subscripts %q, want %q", n, parsed, got, subs)
+			}
+		}
+		for _, bad := range []string{`^x(`, `^x("a"`, `^x("a")b`, `^x("a"")`, `^x(a)`, `^x("a""b)`, `1x("a")`} {
+			if _, err := conn.NodeFromString(bad); !errors.Is(err, ErrInvalidNode) {
+				t.Errorf("NodeFromString(%s) got error %v, want ErrInvalidNode", bad, err)
+			}
+		}
+	})
+	t.Run("MaxSubscripts", func(t *testing.T) {
+		conn := NewConn()
+		subs := make([]string, YDB_MAX_SUBS)
+		for i := range subs {
+			subs[i] = strings.Repeat(strconv.Itoa(i), i+1) // varying lengths expose any misplaced buffer
+		}
+		n := conn.Node("^maxsubs", subs...)
+		if got := n.subscripts(); !slices.Equal(got, subs) {
+			t.Errorf("got subscripts %q, want %q", got, subs)
+		}
+		if err := n.Set("deep"); err != nil {
+			t.Fatal(err)
+		}
+		if val, err := conn.Node("^maxsubs", subs...).Get(); err != nil || val != "deep" {
+			t.Errorf("got %q, %v, want %q", val, err, "deep")
+		}
+		next, err := conn.Node("^maxsubs").NodeNext()
+		if err != nil || !slices.Equal(next.subscripts(), subs) {
+			t.Errorf("got next node %v, %v, want %v", next, err, n)
+		}
+		err = n.Child("extra").Set("too deep")
+		var ydbErr *YDBError
+		if !errors.As(err, &ydbErr) || ydbErr.Code() != YDB_ERR_MAXNRSUBSCRIPTS {
+			t.Errorf("got error %v, want YDB_ERR_MAXNRSUBSCRIPTS", err)
+		}
+		n.Delete()
+	})
+	t.Run("Validate", func(t *testing.T) {
+		conn := NewConn()
+		if err := conn.Node("^valid", "a", "1", "\xff\x00").Validate(); err != nil {
+			t.Errorf("got error %v for a valid node", err)
+		}
+		tooMany := make([]string, YDB_MAX_SUBS+1)
+		for _, test := range []struct {
+			name     string
+			varname  string
+			subs     []string
+			utf8Mode bool
+		}{
+			{"name", "^1abc", nil, false},
+			{"name length", strings.Repeat("a", 32), nil, false},
+			{"subscript count", "^valid", tooMany, false},
+			{"subscript length", "local", []string{strings.Repeat("x", YDB_MAX_STR+1)}, false},
+			{"key length", "^valid", []string{strings.Repeat("x", 600), strings.Repeat("y", 600)}, false},
+			{"key length with escapes", "^valid", []string{strings.Repeat("\x00", 510)}, false},
+			{"binary subscript", "^valid", []string{"ok", "\xff"}, true},
+		} {
+			err := validateKey(test.varname, test.subs, test.utf8Mode)
+			if !errors.Is(err, ErrInvalidNode) {
+				t.Errorf("%s: got error %v, want ErrInvalidNode", test.name, err)
+			}
+		}
+		// The same lengths are valid for a local variable, and binary subscripts in M mode
+		if err := validateKey("local", []string{strings.Repeat("x", 600), strings.Repeat("y", 600), "\xff"}, false); err != nil {
+			t.Errorf("got error %v for a valid local node", err)
+		}
+		if err := validateKey("^valid", []string{strings.Repeat("\x00", 500)}, true); err != nil {
+			t.Errorf("got error %v for a valid key", err)
+		}
+	})
+	t.Run("Data", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("data")
+		n.DeleteTree()
+		for _, step := range []struct {
+			do   func()
+			want int
+		}{
+			{func() {}, 0},
+			{func() { n.Set("x") }, 1},
+			{func() { n.Child("sub").Set("y") }, 11},
+			{func() { n.Delete() }, 10},
+		} {
+			step.do()
+			if got, err := n.Data(); err != nil || got != step.want {
+				t.Errorf("got %d (error %v), want %d", got, err, step.want)
+			}
+		}
+	})
+	t.Run("HasChild", func(t *testing.T) {
+		n := NewConn().Node("haschild")
+		n.DeleteTree()
+		n.Child("value").Set("x")
+		n.Child("tree", "leaf").Set("y")
+		for sub, want := range map[string]bool{"value": true, "tree": true, "leaf": false, "absent": false} {
+			got, err := n.HasChild(sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("child %s: got %v, want %v", sub, got, want)
+			}
+		}
+	})
+	t.Run("ValueLen", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("valuelen")
+		for _, size := range []int{0, 5, 100000} {
+			n.Set(strings.Repeat("x", size))
+			if got, err := n.ValueLen(); err != nil || got != size {
+				t.Errorf("got %d (error %v), want %d", got, err, size)
+			}
+		}
+		n.Delete()
+		if _, err := n.ValueLen(); !isUndef(err) {
+			t.Errorf("got error %v, want LVUNDEF", err)
+		}
+	})
+	t.Run("ValueEquals", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("^valueequals")
+		n.Set("abc")
+		for _, test := range []struct {
+			b    []byte
+			want bool
+		}{
+			{[]byte("abc"), true},
+			{[]byte("abcd"), false},
+			{[]byte("ab"), false},
+			{[]byte("abd"), false},
+			{nil, false},
+		} {
+			if got, err := n.ValueEquals(test.b); err != nil || got != test.want {
+				t.Errorf("%q: got %v, %v, want %v", test.b, got, err, test.want)
+			}
+		}
+		n.Set("")
+		if got, err := n.ValueEquals(nil); err != nil || !got {
+			t.Errorf("got %v, %v comparing empty values, want true", got, err)
+		}
+		n.Delete()
+		if got, err := n.ValueEquals(nil); err != nil || got {
+			t.Errorf("got %v, %v for an absent node, want false with no error", got, err)
+		}
+	})
+	t.Run("ReadChunks", func(t *testing.T) {
+		n := NewConn().Node("chunks")
+		val := strings.Repeat("0123456789", 100001)[:1000000]
+		n.Set(val)
+		for _, size := range []int{1 << 16, 7, len(val), 2 * len(val)} {
+			var got []byte
+			chunks := 0
+			err := n.ReadChunks(size, func(chunk []byte) error {
+				if len(chunk) > size {
+					t.Fatalf("got chunk of %d bytes, want at most %d", len(chunk), size)
+				}
+				got = append(got, chunk...)
+				chunks++
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != val || chunks != (len(val)+size-1)/size {
+				t.Errorf("chunk size %d: got %d bytes in %d chunks, want the original %d bytes", size, len(got), chunks, len(val))
+			}
+		}
+		stop := errors.New("stop")
+		if err := n.ReadChunks(10, func([]byte) error { return stop }); err != stop {
+			t.Errorf("got error %v, want %v", err, stop)
+		}
+		n.Delete()
+		if err := n.ReadChunks(10, func([]byte) error { return nil }); !isUndef(err) {
+			t.Errorf("got error %v, want undefined", err)
+		}
+	})
+	t.Run("Increment", func(t *testing.T) {
+		n := NewConn().Node("incr")
+		n.Delete()
+		for _, step := range []struct{ amount, want string }{{"", "1"}, {"2.5", "3.5"}, {"-4", "-.5"}} {
+			got, err := n.Increment(step.amount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != step.want || n.MustGet() != step.want {
+				t.Errorf("got %s, want %s", got, step.want)
+			}
+		}
+	})
+	t.Run("IncrementDiscard", func(t *testing.T) {
+		n := NewConn().Node("incrdiscard")
+		n.Delete()
+		for _, step := range []struct{ amount, want string }{{"", "1"}, {"2.5", "3.5"}, {"-4", "-.5"}} {
+			if err := n.IncrementDiscard(step.amount); err != nil {
+				t.Fatal(err)
+			}
+			if got := n.MustGet(); got != step.want {
+				t.Errorf("got %s, want %s", got, step.want)
+			}
+		}
+	})
+	t.Run("UndefMode", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("^undefmode")
+		n.Delete()
+		if _, err := n.Get(); !isUndef(err) {
+			t.Errorf("got error %v, want undefined", err)
+		}
+		conn.SetUndefMode(UndefEmptyString)
+		if val, err := n.Get(); val != "" || err != nil {
+			t.Errorf("got %q, %v, want empty string and no error", val, err)
+		}
+		if val, _ := n.Get("default"); val != "default" {
+			t.Errorf("got %s, want %s", val, "default")
+		}
+		n.Child("1").Set("a")
+		if vals, _ := n.GetSlice(); !slices.Equal(vals, []string{"a"}) {
+			t.Errorf("got %v, want %v", vals, []string{"a"})
+		}
+		conn.SetUndefMode(UndefError)
+		if _, err := n.Get(); !isUndef(err) {
+			t.Errorf("got error %v, want undefined", err)
+		}
+	})
+	t.Run("Must", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("must")
+		n.Delete()
+		mustPanic := func(name string, f func()) {
+			defer func() {
+				err, ok := recover().(error)
+				if !ok || !strings.Contains(err.Error(), name) {
+					t.Errorf("got panic %v, want an error mentioning %s", err, name)
+				}
+			}()
+			f()
+		}
+		mustPanic("must", func() { n.MustGet() })
+		if got := n.MustGet("default"); got != "default" {
+			t.Errorf("got %s, want %s", got, "default")
+		}
+		n.MustSet("value")
+		if got := n.MustGet(); got != "value" {
+			t.Errorf("got %s, want %s", got, "value")
+		}
+		mustPanic("$ZVERSION", func() { conn.Node("$ZVERSION").MustSet("x") })
+	})
+	t.Run("KillGlobal", func(t *testing.T) {
+		conn := NewConn()
+		setTree(t, conn.Node("^killme"), map[string]string{"a": "1", "a,b": "2", "c": "3"})
+		conn.Node("^killme").Set("root")
+		conn.Node("^killmenot").Set("keep")
+		if err := conn.KillGlobal("killme"); err != nil {
+			t.Fatal(err)
+		}
+		if data, _ := conn.Node("^killme").Data(); data != 0 {
+			t.Errorf("got data %d after KillGlobal, want 0", data)
+		}
+		if val := conn.Node("^killmenot").MustGet(); val != "keep" {
+			t.Errorf("got %s, want other global left unchanged", val)
+		}
+		for _, name := range []string{"", "^", "^kill me", "$ZVERSION", "^1x"} {
+			if err := conn.KillGlobal(name); !errors.Is(err, ErrInvalidNode) {
+				t.Errorf("got error %v for name %q, want %v", err, name, ErrInvalidNode)
+			}
+		}
+	})
+	t.Run("Delete", func(t *testing.T) {
+		conn := NewConn()
+		n := conn.Node("delete")
+		child := conn.Node("delete", "child")
+		n.Set("parent")
+		child.Set("child")
+		if err := n.Delete(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := n.Get(); err == nil {
+			t.Errorf("got no error getting deleted node %v", n)
+		}
+		if val, _ := child.Get(); val != "child" {
+			t.Errorf("got %s, want %s", val, "child")
+		}
+		n.Set("parent")
+		if err := n.DeleteTree(); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := child.Get(); err == nil {
+			t.Errorf("got no error getting deleted node %v", child)
+		}
+	})
+}
+
+// Test that LastError returns the most recent YottaDB error on the connection only.
+func TestLastError(t *testing.T) {
+	conn := NewConn()
+	other := NewConn()
+	if err := conn.LastError(); err != nil {
+		t.Errorf("got %v before any error, want nil", err)
+	}
+	func() {
+		defer func() { recover() }()
+		conn.Node("$ZVERSION").MustSet("x")
+	}()
+	err := conn.LastError()
+	var ydbErr *YDBError
+	if !errors.As(err, &ydbErr) || ydbErr.Code() != YDB_ERR_SVNOSET || !strings.Contains(ydbErr.Error(), "SVNOSET") {
+		t.Errorf("got %v, want YDB_ERR_SVNOSET", err)
+	}
+	conn.Node("lasterror").Set("ok")
+	if conn.LastError() != err {
+		t.Errorf("got %v after a successful operation, want unchanged %v", conn.LastError(), err)
+	}
+	if err := other.LastError(); err != nil {
+		t.Errorf("got %v on another connection, want nil", err)
+	}
+}
+
+// Test that Exec runs ops in order and identifies the op that failed.
+func TestExec(t *testing.T) {
+	conn := NewConn()
+	var ran []int
+	op := func(i int, err error) func(*Conn) error {
+		return func(*Conn) error {
+			ran = append(ran, i)
+			return err
+		}
+	}
+	failure := errors.New("failure")
+	err := conn.Exec(op(0, nil), op(1, nil), op(2, failure), op(3, nil))
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), "op 2 ") {
+		t.Errorf("got error %v, want %v identifying op 2", err, failure)
+	}
+	if !slices.Equal(ran, []int{0, 1, 2}) {
+		t.Errorf("got ops %v run, want %v", ran, []int{0, 1, 2})
+	}
+	if err := conn.Exec(op(4, nil)); err != nil {
+		t.Error(err)
+	}
+}
+
+// Test that Conn.Set and Conn.Get behave like their Node equivalents, including when the scratch node must grow.
+func TestConnSetGet(t *testing.T) {
+	conn := NewConn()
+	long := strings.Repeat("x", 2*scratchSpare)
+	for _, subs := range [][]string{{"a", "b"}, {}, {"c"}, {long, "d", "e"}, {"f", "g"}} {
+		if err := conn.Set("val"+strings.Join(subs, ","), "^connset", subs...); err != nil {
+			t.Fatal(err)
+		}
+		n := conn.Node("^connset", subs...)
+		if got, want := n.MustGet(), "val"+strings.Join(subs, ","); got != want {
+			t.Errorf("%v: got %q, want %q", n, got, want)
+		}
+		n.Set("node" + strings.Join(subs, ","))
+		if got, err := conn.Get("^connset", subs...); err != nil || got != "node"+strings.Join(subs, ",") {
+			t.Errorf("%v: got %q, %v, want %q", n, got, err, "node"+strings.Join(subs, ","))
+		}
+	}
+	conn.Node("^connset", "none").Delete()
+	_, err := conn.Get("^connset", "none")
+	_, want := conn.Node("^connset", "none").Get()
+	if err == nil || err.Error() != want.Error() {
+		t.Errorf("got error %v, want %v", err, want)
+	}
 }
 
 // --- Benchmarks ---
@@ -71,12 +663,68 @@ func benchmarkSetVariantSubscripts(b *testing.B) {
 	}
 }
 
+// Benchmark repeated String() calls on an immutable node, which caches its result.
+// Benchmark Setting a node given inline to Conn.Set, which reuses a scratch node rather than creating one.
+func benchmarkConnSet(b *testing.B) {
+	subs := make([]string, 5)
+	for i := 0; b.Loop(); i++ {
+		for j := range subs {
+			subs[j] = Randstr()
+		}
+		err := conn.Set(Randstr(), "var", subs...)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Benchmark incrementing a counter with Increment, which returns the result.
+func benchmarkIncrement(b *testing.B) {
+	n := conn.Node("counter")
+	for b.Loop() {
+		if _, err := n.Increment(""); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Benchmark incrementing a counter with IncrementDiscard, which does not return the result.
+func benchmarkIncrementDiscard(b *testing.B) {
+	n := conn.Node("counter")
+	for b.Loop() {
+		if err := n.IncrementDiscard(""); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func benchmarkString(b *testing.B) {
+	n := conn.Node("var", "sub1", "sub2")
+	for b.Loop() {
+		_ = n.String()
+	}
+}
+
+// Benchmark repeated String() calls on a mutable node, which rebuilds its result each time as before caching.
+func benchmarkStringUncached(b *testing.B) {
+	n := &Node{conn: conn}
+	n.alloc("var", []string{"sub1", "sub2"}, mutableSpare)
+	for b.Loop() {
+		_ = n.String()
+	}
+}
+
 // Run all Node benchmarks.
 func BenchmarkNode(b *testing.B) {
 	conn = NewConn()
 
 	b.Run("Set", benchmarkSet)
 	b.Run("SetVariantSubscripts", benchmarkSetVariantSubscripts)
+	b.Run("ConnSet", benchmarkConnSet)
+	b.Run("Increment", benchmarkIncrement)
+	b.Run("IncrementDiscard", benchmarkIncrementDiscard)
+	b.Run("String", benchmarkString)
+	b.Run("StringUncached", benchmarkStringUncached)
 }
 
 // --- Utility functions for tests ---
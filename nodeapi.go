@@ -0,0 +1,37 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// An interface to the basic operations on a node, so that code using them can be tested without YottaDB.
+
+package yottadb
+
+import (
+	"iter"
+)
+
+// NodeAPI is the set of basic operations on a database node. *Node satisfies it.
+// Code that accepts a NodeAPI rather than a *Node can be unit tested with a pure-Go in-memory implementation such
+// as the one in package ydbmock, without a YottaDB engine.
+// Since Go does not allow *Node.Child() to return a NodeAPI, the interface does not include navigation between
+// nodes: pass in each node that the code needs, or use ChildSubscripts() to find the subscripts of its children.
+type NodeAPI interface {
+	String() string
+	Get(deflt ...string) (string, error)
+	Set(val string) error
+	Delete() error
+	DeleteTree() error
+	Increment(amount string) (string, error)
+	Data() (int, error)
+	ChildSubscripts() iter.Seq[string]
+}
+
+var _ NodeAPI = (*Node)(nil)
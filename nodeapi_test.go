@@ -0,0 +1,36 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"slices"
+	"testing"
+)
+
+// Test that *Node can be used through the NodeAPI interface.
+func TestNodeAPI(t *testing.T) {
+	var n NodeAPI = NewConn().Node("^nodeapi")
+	n.DeleteTree()
+	n.Set("value")
+	if count, err := n.Increment("2"); err != nil || count != "2" {
+		t.Errorf("got %s, %v, want %s", count, err, "2")
+	}
+	NewConn().Node("^nodeapi", "b").Set("x")
+	NewConn().Node("^nodeapi", "a").Set("x")
+	if got := slices.Collect(n.ChildSubscripts()); !slices.Equal(got, []string{"a", "b"}) {
+		t.Errorf("got %v, want %v", got, []string{"a", "b"})
+	}
+	if data, _ := n.Data(); data != 11 {
+		t.Errorf("got %d, want %d", data, 11)
+	}
+}
@@ -0,0 +1,69 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Collect sets of distinct nodes
+
+package yottadb
+
+import (
+	"iter"
+)
+
+// NodeSet is a set of distinct database nodes, identified by Node.Key(), that remembers the order in which they were
+// added. It is useful for traversal code that gathers nodes from several iterators and must not process a node twice.
+// The zero value is an empty set ready to use.
+type NodeSet struct {
+	keys  map[string]struct{}
+	nodes []*Node
+}
+
+// Add adds node n to the set unless the set already has a node with the same Key, and reports whether it was added.
+// The set stores an immutable Copy() of n, so mutable nodes yielded by iterators may be added directly.
+func (s *NodeSet) Add(n *Node) bool {
+	key := n.Key()
+	if _, ok := s.keys[key]; ok {
+		return false
+	}
+	if s.keys == nil {
+		s.keys = make(map[string]struct{})
+	}
+	s.keys[key] = struct{}{}
+	s.nodes = append(s.nodes, n.Copy())
+	return true
+}
+
+// Has reports whether the set has a node with the same Key as n.
+func (s *NodeSet) Has(n *Node) bool {
+	_, ok := s.keys[n.Key()]
+	return ok
+}
+
+// Len returns the number of nodes in the set.
+func (s *NodeSet) Len() int {
+	return len(s.nodes)
+}
+
+// Nodes returns the nodes in the set in the order they were added.
+func (s *NodeSet) Nodes() []*Node {
+	return s.nodes
+}
+
+// CollectUnique collects the nodes yielded by seq into a slice, omitting any node with the same Key as one already
+// collected, and keeping the order in which they were first yielded. Mutable nodes are copied as for NodeSet.Add().
+// Use iterators such as Tree() and Children() as seq, or combine several iterators into one to deduplicate across them.
+func CollectUnique(seq iter.Seq[*Node]) []*Node {
+	var set NodeSet
+	for n := range seq {
+		set.Add(n)
+	}
+	return set.Nodes()
+}
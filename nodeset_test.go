@@ -0,0 +1,68 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+// Test that Key distinguishes nodes whose subscripts contain the characters String() escapes.
+func TestKey(t *testing.T) {
+	conn := NewConn()
+	a := conn.Node("key", `a")("b`)
+	b := conn.Node("key", "a", "b")
+	if a.String() == b.String() || a.Key() == b.Key() {
+		t.Errorf("got keys %q and %q for different nodes", a.Key(), b.Key())
+	}
+	if NewConn().Node("key", "a", "b").Key() != b.Key() {
+		t.Errorf("got different keys for the same node on different connections")
+	}
+	if conn.Node("key1").Key() == conn.Node("key", "1").Key() {
+		t.Errorf("got the same key for a varname and a subscript")
+	}
+}
+
+// Test collecting distinct nodes from overlapping iterators.
+func TestCollectUnique(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^nodeset")
+	setTree(t, n, map[string]string{"a": "1", "b": "2", "b,1": "3", "c": "4"})
+	// Children() yields a reused mutable node, so this also checks that the collected nodes are copies
+	both := func(yield func(*Node) bool) {
+		for _, seq := range []iter.Seq[*Node]{n.Children(), n.Tree(), n.Child("b").Tree()} {
+			for node := range seq {
+				if !yield(node) {
+					return
+				}
+			}
+		}
+	}
+	var got []string
+	for _, node := range CollectUnique(both) {
+		got = append(got, node.String())
+	}
+	want := []string{`^nodeset("a")`, `^nodeset("b")`, `^nodeset("c")`, `^nodeset("b")("1")`}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	var set NodeSet
+	if !set.Add(n.Child("a")) || set.Add(conn.Node("^nodeset", "a")) {
+		t.Errorf("got wrong result adding a node twice")
+	}
+	if !set.Has(NewConn().Node("^nodeset", "a")) || set.Has(n.Child("b")) || set.Len() != 1 {
+		t.Errorf("got wrong membership for %v", set.Nodes())
+	}
+}
@@ -0,0 +1,297 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Numeric operations that guard against YottaDB's limited numeric precision.
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"runtime"
+	"strconv"
+	"strings"
+	"unsafe"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// MaxNumericDigits is the number of significant decimal digits that YottaDB numbers retain.
+// Arithmetic results with more significant digits than this are silently rounded by YottaDB.
+const MaxNumericDigits = 18
+
+// ErrNumericOverflow is returned by IncrementInt() and IncrementFloat() when the result would need more than
+// MaxNumericDigits significant digits and would therefore lose precision.
+var ErrNumericOverflow = errors.New("YDB: result exceeds YottaDB numeric precision")
+
+// IncrementInt adds amount to the value of n and returns the result, like Increment(), but returns ErrNumericOverflow
+// instead of storing a result that YottaDB cannot represent exactly, since it has more than MaxNumericDigits
+// significant digits. The increment is done within a transaction so that the check and the update are atomic.
+// If the result is not an integer (because n held a fraction) the value is left unchanged and an error is returned.
+func (n *Node) IncrementInt(amount int64) (int64, error) {
+	result, err := n.incrementExact(strconv.FormatInt(amount, 10), func(s string) error {
+		if _, err := strconv.ParseInt(s, 10, 64); err != nil {
+			return fmt.Errorf("YDB: IncrementInt result %s is not an integer", s)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(result, 10, 64)
+}
+
+// IncrementFloat adds amount to the value of n and returns the result, like IncrementInt().
+// The amount added is the shortest decimal that represents amount as a float64; for example 0.1 adds exactly "0.1".
+// Note that the float64 result has less precision than YottaDB's MaxNumericDigits; use Get() for the exact result.
+func (n *Node) IncrementFloat(amount float64) (float64, error) {
+	result, err := n.incrementExact(strconv.FormatFloat(amount, 'f', -1, 64), nil)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(result, 64)
+}
+
+// NextID returns a new unique integer id from the sequence kept in counter, by incrementing it with IncrementInt()
+// using conn, which need not be the connection counter was created on. Ids start at 1 for a counter with no value, and
+// since the increment is atomic no id is issued twice, even by different processes sharing a global counter.
+func (conn *Conn) NextID(counter *Node) (int64, error) {
+	return counter.on(conn).IncrementInt(1)
+}
+
+// NextIDBlock reserves a contiguous block of n ids from the sequence kept in counter, as NextID() does for a single id,
+// and returns the first and last ids of the block. Reserving ids in blocks takes one database update per block rather
+// than one per id, which suits high-throughput allocation, at the cost of leaving gaps in the sequence where reserved
+// ids go unused.
+func (conn *Conn) NextIDBlock(counter *Node, n int) (start, end int64, err error) {
+	if n < 1 {
+		return 0, 0, fmt.Errorf("YDB: id block size %d is less than 1", n)
+	}
+	end, err = counter.on(conn).IncrementInt(int64(n))
+	if err != nil {
+		return 0, 0, err
+	}
+	return end - int64(n) + 1, end, nil
+}
+
+// incrementExact increments n by amount within a transaction, first checking that the result has at most
+// MaxNumericDigits significant digits. If check is not nil it is also called to validate the result before
+// the transaction commits.
+func (n *Node) incrementExact(amount string, check func(string) error) (string, error) {
+	var result string
+	err := n.conn.Transaction(func(conn *Conn) error {
+		val, err := n.get([]string{"0"})
+		if err != nil {
+			return err
+		}
+		old, err := conn.canonicalNumber(val) // read the value as a number, as Increment() does
+		if err != nil {
+			return err
+		}
+		a, _ := new(big.Rat).SetString(old)
+		b, _ := new(big.Rat).SetString(amount)
+		if a == nil || b == nil {
+			return fmt.Errorf("YDB: cannot increment %q by %q", old, amount)
+		}
+		exact := a.Add(a, b).FloatString(decimalPlaces(old) + decimalPlaces(amount))
+		if significantDigits(exact) > MaxNumericDigits {
+			return fmt.Errorf("%w: %s + %s = %s", ErrNumericOverflow, old, amount, exact)
+		}
+		result, err = n.Increment(amount)
+		if err != nil {
+			return err
+		}
+		if check != nil {
+			return check(result)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// decimalPlaces returns the number of digits after the decimal point in decimal number s.
+func decimalPlaces(s string) int {
+	_, frac, _ := strings.Cut(s, ".")
+	return len(frac)
+}
+
+// significantDigits returns the number of significant digits in decimal number s, from its first non-zero digit to its
+// last non-zero digit. Zero has no significant digits.
+func significantDigits(s string) int {
+	digits := strings.Trim(strings.NewReplacer("-", "", ".", "").Replace(s), "0")
+	return len(digits)
+}
+
+// Max sets n to the numerically larger of its current value and candidate, and returns the resulting value.
+// If n has no value it is set to candidate. The comparison and update are done within a transaction, so concurrent
+// calls from many processes or goroutines correctly track a high-water mark.
+// candidate is stored in YottaDB's canonical form, for example "1E3" is stored as "1000".
+// It returns an error if candidate or the current value of n is not entirely a number as M reads it, such as "1/2".
+func (n *Node) Max(candidate string) (string, error) {
+	return n.extreme(candidate, 1)
+}
+
+// Min sets n to the numerically smaller of its current value and candidate, and returns the resulting value.
+// It is like Max() but tracks a low-water mark.
+func (n *Node) Min(candidate string) (string, error) {
+	return n.extreme(candidate, -1)
+}
+
+// extreme implements Max() when sign is 1 and Min() when sign is -1.
+func (n *Node) extreme(candidate string, sign int) (string, error) {
+	candidate, c, err := n.conn.parseNumber(candidate)
+	if err != nil {
+		return "", fmt.Errorf("YDB: candidate %w", err)
+	}
+	var result string
+	err = n.conn.Transaction(func(conn *Conn) error {
+		val, err := n.get(nil)
+		if isUndef(err) {
+			result = candidate
+			return n.Set(candidate)
+		}
+		if err != nil {
+			return err
+		}
+		_, v, err := conn.parseNumber(val)
+		if err != nil {
+			return fmt.Errorf("YDB: value of %v %w", n, err)
+		}
+		result = val
+		if c.Cmp(v) == sign {
+			result = candidate
+			return n.Set(candidate)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return result, nil
+}
+
+// DecrementFloor subtracts amount from the value of n unless that would make it negative, such as to debit an
+// inventory or credit balance without overdrawing it. It returns the resulting value of n and whether the decrement
+// was applied; if not, n is left unchanged and its current value is returned. A node with no value counts as 0.
+// The check and the decrement are made in one transaction, so concurrent decrements never take the value below zero.
+// amount must be a non-negative number.
+func (n *Node) DecrementFloor(amount string) (string, bool, error) {
+	amount, a, err := n.conn.parseNumber(amount)
+	if err == nil && a.Sign() < 0 {
+		err = fmt.Errorf("%q is negative", amount)
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("YDB: decrement %w", err)
+	}
+	var result string
+	var applied bool
+	err = n.conn.Transaction(func(conn *Conn) error {
+		val, err := n.get([]string{"0"})
+		if err != nil {
+			return err
+		}
+		_, v, err := conn.parseNumber(val)
+		if err != nil {
+			return fmt.Errorf("YDB: value of %v %w", n, err)
+		}
+		result, applied = val, v.Cmp(a) >= 0
+		if !applied {
+			return nil
+		}
+		result, err = n.Increment("-" + amount)
+		return err
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return result, applied, nil
+}
+
+// parseNumber returns the canonical form of s, as YottaDB computes it, and its exact value. It returns an error if s is
+// not entirely a number as M reads it (see isNumeric()), since M would read only a prefix of it: for example "1/2" and
+// "0x10" are read as 1 and 0.
+func (conn *Conn) parseNumber(s string) (string, *big.Rat, error) {
+	if !isNumeric(s) {
+		return "", nil, fmt.Errorf("%q is not a number", s)
+	}
+	canonical, err := conn.canonicalNumber(s)
+	if err != nil {
+		return "", nil, err
+	}
+	r, ok := new(big.Rat).SetString(canonical)
+	if !ok {
+		return "", nil, fmt.Errorf("%q is not a number", s)
+	}
+	return canonical, r, nil
+}
+
+// GetNormalized returns the value of n like Get(), but converted to YottaDB's canonical numeric form if it is a number,
+// for example "007" becomes "7", "1.50" becomes "1.5" and "1E3" becomes "1000". Values that are not entirely a number,
+// such as "abc" or "12 apples", are returned unchanged. This helps compare values that may have been stored in
+// non-canonical form. The conversion is done by YottaDB's own arithmetic so that it matches M's `+value`.
+func (n *Node) GetNormalized() (string, error) {
+	val, err := n.Get()
+	if err != nil || !isNumeric(val) {
+		return val, err
+	}
+	return n.conn.canonicalNumber(val)
+}
+
+// isNumeric reports whether all of s is a number as M reads it: any number of signs, digits with an optional decimal
+// point, and an optional exponent "E" followed by an optionally signed integer.
+func isNumeric(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	mantissa, exponent, hasExp := strings.Cut(s, "E")
+	intPart, fracPart, _ := strings.Cut(mantissa, ".")
+	if intPart == "" && fracPart == "" || hasExp && strings.TrimLeft(exponent, "+-") == "" {
+		return false
+	}
+	if len(exponent) > 0 && (exponent[0] == '+' || exponent[0] == '-') {
+		exponent = exponent[1:]
+	}
+	for _, c := range intPart + fracPart + exponent {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// canonicalNumber returns the canonical form of number s as YottaDB computes it, by using ydb_incr_st() to increment
+// an undefined scratch local variable by s. The scratch variable is unique to conn and is deleted afterwards.
+// The YottaDB API is called directly rather than with Node methods, which would count as writes while probing
+// for a read-only transaction (see SetSkipReadOnlyTP()).
+func (conn *Conn) canonicalNumber(s string) (string, error) {
+	scratch := conn.Node(fmt.Sprintf("%%YDBGOnum%x", uintptr(unsafe.Pointer(conn.c))))
+	// scratch is not used again after this, so keep it alive lest its cleanup free c_n while YottaDB still uses it
+	defer runtime.KeepAlive(scratch)
+	c_n := scratch.n
+	cconn := c_n.conn
+	varname := &c_n.buffers[0]
+	ret := C.ydb_delete_st(cconn.tptoken, &cconn.errstr, varname, 0, nil, C.YDB_DEL_NODE)
+	if ret != C.YDB_OK {
+		return "", conn.Error(ret)
+	}
+	incr := C.ydb_buffer_t{buf_addr: C.CString(s), len_used: C.uint(len(s)), len_alloc: C.uint(len(s))}
+	defer C.free(unsafe.Pointer(incr.buf_addr))
+	ret = C.ydb_incr_st(cconn.tptoken, &cconn.errstr, varname, 0, nil, &incr, &cconn.value)
+	if ret != C.YDB_OK {
+		return "", conn.Error(ret)
+	}
+	result := C.GoStringN(cconn.value.buf_addr, C.int(cconn.value.len_used))
+	C.ydb_delete_st(cconn.tptoken, &cconn.errstr, varname, 0, nil, C.YDB_DEL_NODE)
+	return result, nil
+}
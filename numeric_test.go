@@ -0,0 +1,301 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Test that IncrementInt and IncrementFloat guard against loss of precision.
+func TestIncrementPrecision(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^increment")
+
+	t.Run("Int", func(t *testing.T) {
+		n.Set("999999999999999998") // 18 digits
+		got, err := n.IncrementInt(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != 999999999999999999 {
+			t.Errorf("got %d, want %d", got, int64(999999999999999999))
+		}
+		// 10^18 has only one significant digit, so it is exact
+		if got, err := n.IncrementInt(1); err != nil || got != 1000000000000000000 {
+			t.Errorf("got %d, %v, want %d", got, err, int64(1000000000000000000))
+		}
+		if _, err := n.IncrementInt(1); !errors.Is(err, ErrNumericOverflow) {
+			t.Errorf("got error %v, want %v", err, ErrNumericOverflow)
+		}
+		if val := n.MustGet(); val != "1000000000000000000" {
+			t.Errorf("got %s, want unchanged %s", val, "1000000000000000000")
+		}
+		n.Set("-999999999999999999")
+		if _, err := n.IncrementInt(-1); err != nil {
+			t.Error(err)
+		}
+		if _, err := n.IncrementInt(-1); !errors.Is(err, ErrNumericOverflow) {
+			t.Errorf("got error %v, want %v", err, ErrNumericOverflow)
+		}
+		n.Set("1.5")
+		if _, err := n.IncrementInt(1); err == nil {
+			t.Errorf("got no error incrementing non-integer %s", "1.5")
+		}
+		n.Delete()
+		if got, err := n.IncrementInt(5); err != nil || got != 5 {
+			t.Errorf("got %d, %v, want %d", got, err, 5)
+		}
+		// The check reads the value rather than updating it, so only the increment itself is recorded
+		var buf strings.Builder
+		conn.StartRecording(&buf)
+		n.Set("7 days")
+		if got, err := n.IncrementInt(1); err != nil || got != 8 {
+			t.Errorf("got %d, %v, want %d", got, err, 8)
+		}
+		conn.StopRecording()
+		if want := "S\t^increment\t\"7 days\"\nS\t^increment\t8\n"; buf.String() != want {
+			t.Errorf("got recording %q, want %q", buf.String(), want)
+		}
+	})
+
+	t.Run("Float", func(t *testing.T) {
+		n.Set("12345678901234567") // 17 digits
+		got, err := n.IncrementFloat(0.1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val := n.MustGet(); val != "12345678901234567.1" {
+			t.Errorf("got %s, want %s", val, "12345678901234567.1")
+		}
+		if got != 12345678901234567.1 {
+			t.Errorf("got %v, want %v", got, 12345678901234567.1)
+		}
+		if _, err := n.IncrementFloat(0.01); !errors.Is(err, ErrNumericOverflow) {
+			t.Errorf("got error %v, want %v", err, ErrNumericOverflow)
+		}
+		n.Set("0")
+		if got, err := n.IncrementFloat(-0.25); err != nil || got != -0.25 {
+			t.Errorf("got %v, %v, want %v", got, err, -0.25)
+		}
+	})
+}
+
+// Test that concurrent calls to Max and Min track the true extremes.
+func TestMaxMin(t *testing.T) {
+	const goroutines, calls = 8, 50
+	candidate := func(g, i int) int { return (g*calls+i)*7919%1000 - 500 }
+	wantMax, wantMin := math.MinInt, math.MaxInt
+	for g := range goroutines {
+		for i := range calls {
+			wantMax, wantMin = max(wantMax, candidate(g, i)), min(wantMin, candidate(g, i))
+		}
+	}
+
+	conn := NewConn()
+	high, low := conn.Node("^maxmin", "high"), conn.Node("^maxmin", "low")
+	high.Delete()
+	low.Delete()
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn := NewConn()
+			high, low := conn.Node("^maxmin", "high"), conn.Node("^maxmin", "low")
+			for i := range calls {
+				c := strconv.Itoa(candidate(g, i))
+				if _, err := high.Max(c); err != nil {
+					t.Error(err)
+					return
+				}
+				if _, err := low.Min(c); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got := high.MustGet(); got != strconv.Itoa(wantMax) {
+		t.Errorf("got max %s, want %d", got, wantMax)
+	}
+	if got := low.MustGet(); got != strconv.Itoa(wantMin) {
+		t.Errorf("got min %s, want %d", got, wantMin)
+	}
+	if got, _ := high.Max("-1000"); got != strconv.Itoa(wantMax) {
+		t.Errorf("got %s, want unchanged %d", got, wantMax)
+	}
+	for _, bad := range []string{"abc", "1/2", "0x10", "1e3", "12 apples"} {
+		if _, err := high.Max(bad); err == nil {
+			t.Errorf("got no error for non-numeric candidate %q", bad)
+		}
+	}
+	// Candidates are compared and stored in canonical form
+	if got, err := high.Max("1E3"); err != nil || got != "1000" || high.MustGet() != "1000" {
+		t.Errorf("got %s, %v, stored %s; want 1000", got, err, high.MustGet())
+	}
+	low.Delete()
+	if got, err := low.Min("-007.50"); err != nil || got != "-7.5" {
+		t.Errorf("got %s, %v; want -7.5", got, err)
+	}
+}
+
+// Test that racing decrements with DecrementFloor never take a balance below zero.
+func TestDecrementFloor(t *testing.T) {
+	const balance, goroutines, calls = 25, 8, 10
+	conn := NewConn()
+	n := conn.Node("^decfloor")
+	n.Set(strconv.Itoa(balance))
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	applied := 0
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := NewConn().Node("^decfloor")
+			for range calls {
+				_, ok, err := n.DecrementFloor("2")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if ok {
+					mu.Lock()
+					applied++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if applied != balance/2 {
+		t.Errorf("got %d decrements applied, want %d", applied, balance/2)
+	}
+	if got := n.MustGet(); got != "1" {
+		t.Errorf("got balance %s, want 1", got)
+	}
+	if val, ok, err := n.DecrementFloor("1"); err != nil || !ok || val != "0" {
+		t.Errorf("got %s, %v, %v, want 0 applied", val, ok, err)
+	}
+	if val, ok, err := n.DecrementFloor(".5"); err != nil || ok || val != "0" {
+		t.Errorf("got %s, %v, %v, want 0 not applied", val, ok, err)
+	}
+	n.Delete()
+	if val, ok, err := n.DecrementFloor("1"); err != nil || ok || val != "0" {
+		t.Errorf("got %s, %v, %v for an absent node, want 0 not applied", val, ok, err)
+	}
+	if _, _, err := n.DecrementFloor("-1"); err == nil {
+		t.Errorf("got no error for a negative amount")
+	}
+	// M would read "1/2" as 1, so it must not be checked as .5
+	n.Set(".5")
+	for _, bad := range []string{"1/2", "0x10", "1e3"} {
+		if _, _, err := n.DecrementFloor(bad); err == nil {
+			t.Errorf("got no error for non-numeric amount %q", bad)
+		}
+	}
+	if val, ok, err := n.DecrementFloor("+.50"); err != nil || !ok || val != "0" {
+		t.Errorf("got %s, %v, %v, want 0 applied", val, ok, err)
+	}
+}
+
+// Test that NextID and NextIDBlock never issue the same id twice, even concurrently.
+func TestNextID(t *testing.T) {
+	counter := NewConn().Node("^nextid")
+	counter.Delete()
+	if _, _, err := NewConn().NextIDBlock(counter, 0); err == nil {
+		t.Errorf("got no error for an empty block")
+	}
+
+	const goroutines, rounds, blockSize = 8, 50, 5
+	var mu sync.Mutex
+	issued := make(map[int64]bool)
+	issue := func(start, end int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		for id := start; id <= end; id++ {
+			if issued[id] {
+				t.Errorf("id %d issued twice", id)
+			}
+			issued[id] = true
+		}
+	}
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn := NewConn()
+			for range rounds {
+				id, err := conn.NextID(counter) // counter belongs to another Conn
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				issue(id, id)
+				start, end, err := conn.NextIDBlock(counter, blockSize)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if end-start+1 != blockSize {
+					t.Errorf("got block %d-%d, want %d ids", start, end, blockSize)
+				}
+				issue(start, end)
+			}
+		}()
+	}
+	wg.Wait()
+	// No gaps either, since every reserved id was recorded
+	total := goroutines * rounds * (1 + blockSize)
+	for id := int64(1); id <= int64(total); id++ {
+		if !issued[id] {
+			t.Errorf("id %d was not issued", id)
+			break
+		}
+	}
+	if len(issued) != total {
+		t.Errorf("got %d ids, want %d", len(issued), total)
+	}
+}
+
+// Test that GetNormalized converts numbers to canonical form and leaves other values unchanged.
+func TestGetNormalized(t *testing.T) {
+	n := NewConn().Node("normalized")
+	for _, test := range []struct{ val, want string }{
+		{"007", "7"},
+		{"1.50", "1.5"},
+		{"-0.250", "-.25"},
+		{"+12", "12"},
+		{"--3", "3"},
+		{".5000", ".5"},
+		{"1E3", "1000"},
+		{"-0", "0"},
+		{"42", "42"},
+		{"abc", "abc"},
+		{"12 apples", "12 apples"},
+		{"1.2.3", "1.2.3"},
+		{"", ""},
+	} {
+		n.Set(test.val)
+		if got, err := n.GetNormalized(); err != nil || got != test.want {
+			t.Errorf("normalizing %q: got %q, %v, want %q", test.val, got, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Deliver changes to a node's value on a channel.
+
+package yottadb
+
+import (
+	"context"
+	"time"
+)
+
+// Observe returns a channel that delivers the new value of n each time it changes, as seen by polling n every interval,
+// and that is closed when ctx is done. A node without a value reads as "", so deleting the value of n delivers "" and
+// setting it to "" delivers nothing.
+// The channel holds one value, and the latest value wins: if the receiver has not taken a value by the time the next
+// change is seen, the older value is dropped. So a slow receiver always gets the current value but may skip
+// intermediate ones, as may any receiver when n changes more than once between polls.
+// Since a Conn may not be used by more than one goroutine at a time, the polling goroutine makes its own connection
+// rather than use that of n. Errors reading n are ignored and the read is simply tried again after the next interval.
+func (n *Node) Observe(ctx context.Context, interval time.Duration) <-chan string {
+	ch := make(chan string, 1)
+	node := n.on(NewConn())
+	last, _ := node.Get("") // read now so that changes made once Observe returns are delivered
+	go func() {
+		defer close(ch)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			val, err := node.Get("")
+			if err != nil || val == last {
+				continue
+			}
+			last = val
+			select {
+			case <-ch: // drop the value the receiver has not taken
+			default:
+			}
+			ch <- val
+		}
+	}()
+	return ch
+}
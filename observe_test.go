@@ -0,0 +1,61 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// Test that Observe delivers each change in order, keeps only the latest undelivered value, and closes when done.
+func TestObserve(t *testing.T) {
+	n := NewConn().Node("^observe")
+	n.Set("initial")
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := n.Observe(ctx, time.Millisecond)
+
+	receive := func() string {
+		select {
+		case val := <-ch:
+			return val
+		case <-time.After(time.Second):
+			t.Fatal("timed out waiting for a change")
+		}
+		return ""
+	}
+	for _, val := range []string{"one", "two", "three"} {
+		n.Set(val)
+		if got := receive(); got != val {
+			t.Errorf("got %q, want %q", got, val)
+		}
+	}
+	n.Delete()
+	if got := receive(); got != "" {
+		t.Errorf("got %q after deleting, want the empty string", got)
+	}
+
+	// Changes the receiver does not keep up with leave only the latest value
+	for _, val := range []string{"a", "b", "latest"} {
+		n.Set(val)
+		time.Sleep(20 * time.Millisecond)
+	}
+	if got := receive(); got != "latest" {
+		t.Errorf("got %q, want %q", got, "latest")
+	}
+
+	cancel()
+	for range ch {
+		// drain any change seen before cancellation
+	}
+}
@@ -0,0 +1,62 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Chain database updates, checking for an error only at the end.
+
+package yottadb
+
+// Pipe chains database updates so that code that makes many updates in sequence, such as to set up test data, need not
+// check for an error after each one. It records the first error returned, after which it skips the remaining
+// operations, so that Err() reports it at the end:
+//
+//	err := conn.Pipe().Set(a, "1").Incr(b, "").Delete(c).Err()
+//
+// The updates are made one at a time using the Conn of the Pipe, not within a transaction, so those made before an
+// error remain made; run the pipeline within Transaction() to make them all or none.
+type Pipe struct {
+	conn *Conn
+	err  error
+}
+
+// Pipe returns a new Pipe that makes updates using conn.
+func (conn *Conn) Pipe() *Pipe {
+	return &Pipe{conn: conn}
+}
+
+// Set sets the value of node to val, unless an earlier operation failed. See Node.Set().
+func (p *Pipe) Set(node *Node, val string) *Pipe {
+	if p.err == nil {
+		p.err = node.on(p.conn).Set(val)
+	}
+	return p
+}
+
+// Delete deletes the value of node, unless an earlier operation failed. See Node.Delete().
+func (p *Pipe) Delete(node *Node) *Pipe {
+	if p.err == nil {
+		p.err = node.on(p.conn).Delete()
+	}
+	return p
+}
+
+// Incr adds amount to the value of node, unless an earlier operation failed. See Node.Increment().
+func (p *Pipe) Incr(node *Node, amount string) *Pipe {
+	if p.err == nil {
+		p.err = node.on(p.conn).IncrementDiscard(amount)
+	}
+	return p
+}
+
+// Err returns the first error returned by an operation of p, or nil if they all succeeded.
+func (p *Pipe) Err() error {
+	return p.err
+}
@@ -0,0 +1,53 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"testing"
+)
+
+// Test a pipeline of updates that succeeds, and one that stops at its first error.
+func TestPipe(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^pipe")
+	n.DeleteTree()
+	n.Child("old").Set("x")
+	err := conn.Pipe().Set(n.Child("a"), "1").Incr(n.Child("count"), "2").Incr(n.Child("count"), "").Delete(n.Child("old")).Err()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := n.Child("a").Get(); got != "1" {
+		t.Errorf("got a=%q, want %q", got, "1")
+	}
+	if got, _ := n.Child("count").Get(); got != "3" {
+		t.Errorf("got count=%q, want %q", got, "3")
+	}
+	if data, _ := n.Child("old").Data(); data != 0 {
+		t.Errorf("got old still defined")
+	}
+
+	tooDeep := conn.Node("^pipe", make([]string, YDB_MAX_SUBS+1)...)
+	err = conn.Pipe().Set(n.Child("b"), "1").Set(tooDeep, "x").Set(n.Child("c"), "1").Delete(n.Child("a")).Err()
+	if err == nil {
+		t.Fatal("got no error from an invalid node")
+	}
+	if got, _ := n.Child("b").Get(); got != "1" {
+		t.Errorf("got b=%q, want the update before the error to be made", got)
+	}
+	if data, _ := n.Child("c").Data(); data != 0 {
+		t.Errorf("got c set after the error")
+	}
+	if got, _ := n.Child("a").Get(); got != "1" {
+		t.Errorf("got a=%q, want the delete after the error to be skipped", got)
+	}
+}
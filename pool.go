@@ -0,0 +1,139 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Share connections between goroutines by giving each goroutine its own Conn from a pool
+
+package yottadb
+
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ConnPool holds connections that are not currently in use so that goroutines can reuse them rather than create a new
+// Conn each time. A Conn taken from the pool with Get() belongs to that goroutine alone until it is returned with Put().
+// This is the safe way to run database operations concurrently: unlike SyncConn, goroutines using their own Conn do not
+// wait for each other. For example, with golang.org/x/sync/errgroup each goroutine uses its own Conn and transaction:
+//
+//	g.Go(func() error {
+//		conn := pool.Get()
+//		defer pool.Put(conn)
+//		return conn.Transaction(func(conn *Conn) error { ... })
+//	})
+//
+// RunParallel() codifies this pattern.
+type ConnPool struct {
+	mu    sync.Mutex
+	free  []*Conn
+	slots chan struct{} // holds a value for each connection acquired with Acquire(), if the pool has a capacity
+}
+
+// NewConnPool creates an empty pool of connections. If capacity[0] is supplied and > 0, at most that many connections
+// may be in use at once through Acquire(), which then waits for a Release() rather than create more.
+func NewConnPool(capacity ...int) *ConnPool {
+	p := &ConnPool{}
+	if len(capacity) > 0 && capacity[0] > 0 {
+		p.slots = make(chan struct{}, capacity[0])
+	}
+	return p
+}
+
+// Acquire takes a connection from the pool like Get(), but if the pool has a capacity (see NewConnPool()) and that many
+// connections are already acquired, it waits until one is released with Release(), or until ctx is done, in which
+// case it returns ctx.Err(). This gives servers backpressure under load rather than an unbounded number of connections.
+// Connections taken with Get() do not count towards the capacity.
+func (p *ConnPool) Acquire(ctx context.Context) (*Conn, error) {
+	if p.slots != nil {
+		select {
+		case p.slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return p.Get(), nil
+}
+
+// Release returns a connection taken with Acquire() to the pool, letting a waiting Acquire() proceed.
+// The caller must not use conn, or any Node created from it, afterwards.
+func (p *ConnPool) Release(conn *Conn) {
+	p.Put(conn)
+	if p.slots != nil {
+		<-p.slots
+	}
+}
+
+// Get takes a connection from the pool, creating a new one if the pool is empty.
+func (p *ConnPool) Get() *Conn {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.free) == 0 {
+		return NewConn()
+	}
+	conn := p.free[len(p.free)-1]
+	p.free = p.free[:len(p.free)-1]
+	return conn
+}
+
+// Put returns conn to the pool for reuse. The caller must not use conn, or any Node created from it, afterwards.
+// The settings of conn are reset to those of a new Conn, so that the next user does not inherit them: its handlers
+// (OnCommit, OnRestart), recording, codec, undefined-value mode, retry policy, default timeout, and the
+// SetSkipReadOnlyTP and SetVerifyReads options.
+func (p *ConnPool) Put(conn *Conn) {
+	conn.reset()
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.free = append(p.free, conn)
+}
+
+// reset restores the settings of conn to those of a new Conn, keeping its buffers.
+func (conn *Conn) reset() {
+	conn.timeout, conn.undefMode, conn.skipTP, conn.verifyReads = 0, UndefError, false, false
+	conn.codec, conn.onCommit, conn.onRestart, conn.retryPolicy = nil, nil, nil, nil
+	conn.recorder, conn.recordErr, conn.lastErr = nil, nil, nil
+	conn.txStats, conn.restartReason = TxStats{}, RestartNone
+}
+
+// RunParallel calls fn(conn, i) for each i from 0 to n-1 and waits for them all to finish. The calls are shared out
+// between workers[0] goroutines if supplied and > 0, or else runtime.GOMAXPROCS(0), so that a large n does not create a
+// goroutine and a Conn for every call. Each goroutine takes its own conn from pool and passes it to each of its calls,
+// resetting its settings between calls as Put() does. RunParallel returns the first error returned by any call, like
+// errgroup.Group.Wait(), but still makes the remaining calls.
+// Each fn must do its database operations using only the conn it is given, for example by running its own
+// conn.Transaction(), and must not share nodes with other calls.
+func RunParallel(pool *ConnPool, n int, fn func(conn *Conn, i int) error, workers ...int) error {
+	count := runtime.GOMAXPROCS(0)
+	if len(workers) > 0 && workers[0] > 0 {
+		count = workers[0]
+	}
+	var wg sync.WaitGroup
+	var once sync.Once
+	var first error
+	var next atomic.Int64 // next index to hand out
+	for range min(count, n) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn := pool.Get()
+			defer pool.Put(conn)
+			for i := int(next.Add(1) - 1); i < n; i = int(next.Add(1) - 1) {
+				if err := fn(conn, i); err != nil {
+					once.Do(func() { first = err })
+				}
+				conn.reset()
+			}
+		}()
+	}
+	wg.Wait()
+	return first
+}
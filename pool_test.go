@@ -0,0 +1,155 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test running many transactions in parallel, each with its own Conn from a pool.
+func TestRunParallel(t *testing.T) {
+	pool := NewConnPool()
+	pool.Get().Node("^parallel").DeleteTree()
+	const n, groups = 100, 5
+	err := RunParallel(pool, n, func(conn *Conn, i int) error {
+		return conn.Transaction(func(conn *Conn) error {
+			total := conn.Node("^parallel", "total")
+			group := conn.Node("^parallel", strconv.Itoa(i%groups))
+			if _, err := total.Increment(strconv.Itoa(i)); err != nil {
+				return err
+			}
+			_, err := group.Increment("")
+			return err
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn := pool.Get()
+	if got := conn.Node("^parallel", "total").MustGet(); got != strconv.Itoa(n*(n-1)/2) {
+		t.Errorf("got total %s, want %d", got, n*(n-1)/2)
+	}
+	for g := range groups {
+		if got := conn.Node("^parallel", strconv.Itoa(g)).MustGet(); got != strconv.Itoa(n/groups) {
+			t.Errorf("group %d: got count %s, want %d", g, got, n/groups)
+		}
+	}
+	pool.Put(conn)
+
+	failure := errors.New("failure")
+	pooled := len(pool.free) // as many as the first run needed at once
+	err = RunParallel(pool, 10, func(conn *Conn, i int) error {
+		if i == 7 {
+			return failure
+		}
+		return nil
+	})
+	if err != failure {
+		t.Errorf("got error %v, want %v", err, failure)
+	}
+	if len(pool.free) < pooled || len(pool.free) > pooled+10 {
+		t.Errorf("got %d pooled connections, want between %d and %d", len(pool.free), pooled, pooled+10)
+	}
+
+	// Many calls share a few workers, each with one conn
+	pool = NewConnPool()
+	var running, most atomic.Int32
+	var calls atomic.Int32
+	err = RunParallel(pool, 1000, func(conn *Conn, i int) error {
+		calls.Add(1)
+		if r := running.Add(1); r > most.Load() {
+			most.Store(r)
+		}
+		time.Sleep(10 * time.Microsecond)
+		running.Add(-1)
+		return nil
+	}, 3)
+	if err != nil || calls.Load() != 1000 {
+		t.Errorf("got %d calls, error %v; want 1000 calls", calls.Load(), err)
+	}
+	if most.Load() > 3 || len(pool.free) > 3 {
+		t.Errorf("got %d concurrent calls and %d connections, want at most 3 workers", most.Load(), len(pool.free))
+	}
+}
+
+// Test that a connection returned to the pool does not pass its settings on to the next user.
+func TestPutResets(t *testing.T) {
+	pool := NewConnPool()
+	conn := pool.Get()
+	conn.SetUndefMode(UndefEmptyString)
+	conn.OnCommit(func([]*Node) {})
+	conn.WithRetry(RetryPolicy{MaxAttempts: 3})
+	conn.SetVerifyReads(true)
+	conn.StartRecording(io.Discard)
+	pool.Put(conn)
+	conn = pool.Get()
+	if conn.undefMode != UndefError || conn.onCommit != nil || conn.retryPolicy != nil || conn.verifyReads || conn.recorder != nil {
+		t.Errorf("got settings of the previous user on a pooled connection")
+	}
+	if _, err := conn.Node("^putresets", "absent").Get(); !isUndef(err) {
+		t.Errorf("got error %v, want GVUNDEF", err)
+	}
+}
+
+// Test that Acquire on a pool with a capacity waits for a Release, and gives up when its context is cancelled.
+func TestAcquire(t *testing.T) {
+	pool := NewConnPool(2)
+	ctx := context.Background()
+	a, _ := pool.Acquire(ctx)
+	b, err := pool.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a == b {
+		t.Errorf("got the same connection acquired twice")
+	}
+
+	acquired := make(chan *Conn)
+	go func() {
+		conn, _ := pool.Acquire(ctx)
+		acquired <- conn
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("got a connection beyond the pool's capacity")
+	case <-time.After(20 * time.Millisecond):
+	}
+	pool.Release(a)
+	select {
+	case conn := <-acquired:
+		pool.Release(conn)
+	case <-time.After(time.Second):
+		t.Fatal("got no connection after a release")
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	pool.Acquire(ctx) // fill the pool again
+	if conn, err := pool.Acquire(timeout); conn != nil || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got %v, %v, want context.DeadlineExceeded", conn, err)
+	}
+
+	// A pool without a capacity never waits
+	unlimited := NewConnPool()
+	for range 5 {
+		if _, err := unlimited.Acquire(timeout); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
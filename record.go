@@ -0,0 +1,140 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Record database updates to a replayable log and replay them.
+
+package yottadb
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// Operations in a recording made by StartRecording().
+const (
+	recordSet        = "S" // set the node to the value that follows its subscripts
+	recordDelete     = "D" // delete the value of the node
+	recordDeleteTree = "K" // delete the node and its subtree
+)
+
+// StartRecording makes conn write a record of each database update it makes to w, so that the updates can be applied
+// again later with Replay(), perhaps to a different database, for debugging, migration or an audit trail.
+// Each record is one line of tab-separated fields: the operation (S to set, D to delete a value, or K to delete a
+// tree), the varname, each subscript in ZWRITE format (see Str2Zwr()) and, for S, the value in ZWRITE format.
+// ZWRITE format writes tabs and newlines as $C() expressions, so the fields need no further escaping.
+// Updates are made with Set, Delete, DeleteTree and Increment (and functions built on them). An Increment is recorded
+// as a Set of its result, so that replaying it reproduces the same value. Updates made within a transaction are only
+// recorded when the outermost transaction commits (see Defer()), so rolled back updates are not recorded.
+// Recording replaces any previous recording on conn. If writing to w fails, recording stops and StopRecording()
+// returns the error.
+func (conn *Conn) StartRecording(w io.Writer) {
+	conn.recorder, conn.recordErr = w, nil
+}
+
+// StopRecording stops the recording started by StartRecording() and returns the first error encountered writing it.
+func (conn *Conn) StopRecording() error {
+	err := conn.recordErr
+	conn.recorder, conn.recordErr = nil, nil
+	return err
+}
+
+// recordUpdate writes to the recording of conn, if any, that op was applied to n, with val as the value for recordSet.
+func (conn *Conn) recordUpdate(op string, n *Node, val string) {
+	if conn.recorder == nil {
+		return
+	}
+	// Format the record now since n may be a mutable node that changes before the record is written
+	fields := []string{op, n.varname()}
+	strs := n.subscripts()
+	if op == recordSet {
+		strs = append(strs, val)
+	}
+	for _, s := range strs {
+		zwr, err := conn.Str2Zwr(s)
+		if err != nil {
+			conn.recordErr, conn.recorder = err, nil
+			return
+		}
+		fields = append(fields, zwr)
+	}
+	line := strings.Join(fields, "\t") + "\n"
+	w := conn.recorder
+	conn.Defer(func() {
+		if conn.recorder != w {
+			return // recording stopped or restarted before the transaction committed
+		}
+		if _, err := io.WriteString(w, line); err != nil {
+			conn.recordErr, conn.recorder = err, nil
+		}
+	})
+}
+
+// Replay applies to the database, using conn, each update recorded in r by StartRecording(), in order.
+// It stops at the first record that is malformed or fails to apply, and returns an error that gives its line number.
+// The updates are not made within a transaction, so those before the failing record remain applied; call Replay
+// within Transaction() to apply a recording all or nothing.
+func (conn *Conn) Replay(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	// A value may be up to YDB_MAX_STR long, and ZWRITE format may make it several times longer
+	scanner.Buffer(nil, 16*C.YDB_MAX_STR)
+	for line := 1; scanner.Scan(); line++ {
+		if err := conn.replayRecord(scanner.Text()); err != nil {
+			return fmt.Errorf("YDB: replaying line %d: %w", line, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// replayRecord applies one line of a recording made by StartRecording().
+func (conn *Conn) replayRecord(record string) error {
+	fields := strings.Split(record, "\t")
+	if len(fields) < 2 {
+		return fmt.Errorf("invalid record %q", record)
+	}
+	op, varname, strs := fields[0], fields[1], fields[2:]
+	if op == recordSet {
+		if len(strs) == 0 {
+			return fmt.Errorf("record %q has no value", record)
+		}
+	} else if op != recordDelete && op != recordDeleteTree {
+		return fmt.Errorf("invalid operation %q", op)
+	}
+	for i, zwr := range strs {
+		s, err := conn.Zwr2Str(zwr)
+		if err != nil {
+			return err
+		}
+		strs[i] = s
+	}
+	var val string
+	if op == recordSet {
+		val, strs = strs[len(strs)-1], strs[:len(strs)-1]
+	}
+	n, err := conn.NodeOpts(NodeOptions{Varname: varname, Subscripts: strs, Validate: true})
+	if err != nil {
+		return err
+	}
+	switch op {
+	case recordSet:
+		return n.Set(val)
+	case recordDelete:
+		return n.Delete()
+	}
+	return n.DeleteTree()
+}
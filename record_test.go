@@ -0,0 +1,75 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"bytes"
+	"maps"
+	"strings"
+	"testing"
+)
+
+// Test recording a series of updates, clearing the database, and replaying them to reproduce the same state.
+func TestRecordReplay(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^record")
+	n.DeleteTree()
+	var buf bytes.Buffer
+	conn.StartRecording(&buf)
+	n.Child("a").Set("1")
+	n.Child("tab\there", "new\nline").Set("value with\ttab and \"quotes\"")
+	n.Child("bin").Set("\x00\xff")
+	n.Child("count").Increment("5")
+	n.Child("count").Increment("")
+	n.Child("gone", "x").Set("1")
+	n.Child("gone").DeleteTree()
+	n.Child("a").Delete()
+	n.Child("a").Set("2")
+	conn.Transaction(func(conn *Conn) error {
+		return n.Child("committed").Set("yes")
+	})
+	conn.Transaction(func(conn *Conn) error {
+		n.Child("rolledback").Set("no")
+		return ErrRollback
+	})
+	if err := conn.StopRecording(); err != nil {
+		t.Fatal(err)
+	}
+	n.Child("after").Set("not recorded")
+	if strings.Contains(buf.String(), "rolledback") || strings.Contains(buf.String(), "after") {
+		t.Errorf("got recording of updates that were rolled back or made after recording stopped:\n%s", buf.String())
+	}
+
+	want := map[string]string{}
+	for node := range n.Tree() {
+		want[node.String()] = node.MustGet()
+	}
+	delete(want, `^record("after")`)
+	n.DeleteTree()
+	if err := conn.Replay(bytes.NewReader(buf.Bytes())); err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]string{}
+	for node := range n.Tree() {
+		got[node.String()] = node.MustGet()
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	for _, bad := range []string{"S\t^record\n", "X\t^record\n", "S\t^1bad\t\"v\"\n"} {
+		if err := conn.Replay(strings.NewReader(bad)); err == nil {
+			t.Errorf("got no error replaying %q", bad)
+		}
+	}
+}
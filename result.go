@@ -0,0 +1,77 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Values returned together with the node they came from, with typed accessors.
+
+package yottadb
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Result is the value of a node as returned by GetResult(), together with the node it came from so that conversion
+// errors can say where the value was stored.
+type Result struct {
+	Value string // the value of Node, or "" if it has none
+	Node  *Node  // an immutable copy of the node that was read
+	Found bool   // whether Node has a value
+}
+
+// GetResult returns the value of n as a Result. A node with no value is not an error: GetResult then returns a
+// Result with Found set to false. Other YottaDB errors are returned as is.
+func (n *Node) GetResult() (Result, error) {
+	val, err := n.get(nil)
+	if err != nil && !isUndef(err) {
+		return Result{}, err
+	}
+	return Result{Value: val, Node: n.Copy(), Found: err == nil}, nil
+}
+
+// String returns the value of r, or "" if it has none.
+func (r Result) String() string {
+	return r.Value
+}
+
+// Bytes returns the value of r as a byte slice, or nil if it has none.
+func (r Result) Bytes() []byte {
+	if !r.Found {
+		return nil
+	}
+	return []byte(r.Value)
+}
+
+// Int returns the value of r as an integer. It returns an error naming the node if the node has no value or its value
+// is not a decimal integer that fits in an int64.
+func (r Result) Int() (int64, error) {
+	if !r.Found {
+		return 0, fmt.Errorf("YDB: %v has no value", r.Node)
+	}
+	i, err := strconv.ParseInt(r.Value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("YDB: value %q of %v is not an integer", r.Value, r.Node)
+	}
+	return i, nil
+}
+
+// Float returns the value of r as a float64. It returns an error naming the node if the node has no value or its value
+// is not a number.
+func (r Result) Float() (float64, error) {
+	if !r.Found {
+		return 0, fmt.Errorf("YDB: %v has no value", r.Node)
+	}
+	f, err := strconv.ParseFloat(r.Value, 64)
+	if err != nil {
+		return 0, fmt.Errorf("YDB: value %q of %v is not a number", r.Value, r.Node)
+	}
+	return f, nil
+}
@@ -0,0 +1,67 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// Test GetResult and the typed accessors of Result.
+func TestGetResult(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^result")
+	n.DeleteTree()
+	n.Child("int").Set("-42")
+	n.Child("float").Set("2.5")
+	n.Child("text").Set("abc")
+
+	r, err := n.Child("int").GetResult()
+	if err != nil || !r.Found || r.String() != "-42" || r.Node.String() != `^result("int")` {
+		t.Fatalf("got %+v, %v", r, err)
+	}
+	if i, err := r.Int(); err != nil || i != -42 {
+		t.Errorf("got Int %d, %v, want -42", i, err)
+	}
+	if f, err := r.Float(); err != nil || f != -42 {
+		t.Errorf("got Float %g, %v, want -42", f, err)
+	}
+
+	r, _ = n.Child("float").GetResult()
+	if f, err := r.Float(); err != nil || f != 2.5 {
+		t.Errorf("got Float %g, %v, want 2.5", f, err)
+	}
+	if _, err := r.Int(); err == nil {
+		t.Errorf("got no error from Int of %q", r.Value)
+	}
+
+	r, _ = n.Child("text").GetResult()
+	if b := r.Bytes(); !bytes.Equal(b, []byte("abc")) {
+		t.Errorf("got Bytes %q, want %q", b, "abc")
+	}
+	if _, err := r.Float(); err == nil || !strings.Contains(err.Error(), `^result("text")`) {
+		t.Errorf("got error %v, want it to name the node", err)
+	}
+
+	r, err = n.Child("absent").GetResult()
+	if err != nil || r.Found || r.String() != "" || r.Bytes() != nil {
+		t.Errorf("got %+v, %v for an absent node", r, err)
+	}
+	if _, err := r.Int(); err == nil || !strings.Contains(err.Error(), `^result("absent")`) {
+		t.Errorf("got error %v, want it to name the node", err)
+	}
+	if _, err := r.Float(); err == nil {
+		t.Errorf("got no error from Float of an absent node")
+	}
+}
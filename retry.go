@@ -0,0 +1,98 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Retry database operations that fail with transient errors.
+
+package yottadb
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// TransientErrors are the YottaDB error codes that a RetryPolicy retries by default, since they report a temporary
+// condition that may clear if the operation is simply tried again:
+//   - YDB_ERR_TPRETRY: a restart requested by YottaDB reached code outside the transaction that could act on it.
+//   - YDB_ERR_LOCKSPACEFULL: the lock table is temporarily full, until other processes release locks.
+//   - YDB_ERR_SEMWT2LONG: a process waited too long for a semaphore held by another process.
+var TransientErrors = []int{int(C.YDB_ERR_TPRETRY), int(C.YDB_ERR_LOCKSPACEFULL), int(C.YDB_ERR_SEMWT2LONG)}
+
+// RetryPolicy specifies how to retry operations that fail with transient errors; see Conn.WithRetry().
+type RetryPolicy struct {
+	MaxAttempts int           // total number of attempts, including the first; less than 2 means never retry
+	Backoff     time.Duration // wait before the first retry, which doubles before each later retry
+	MaxBackoff  time.Duration // if > 0, the longest wait between attempts
+	Codes       []int         // YottaDB error codes to retry; nil means TransientErrors
+}
+
+// retryWait returns how long to wait before retrying attempt number attempt (counting from 1), which failed with
+// YottaDB error code, and whether to retry it at all.
+func (p *RetryPolicy) retryWait(code int, attempt int) (time.Duration, bool) {
+	codes := p.Codes
+	if codes == nil {
+		codes = TransientErrors
+	}
+	if attempt >= p.MaxAttempts || !slices.Contains(codes, code) {
+		return 0, false
+	}
+	wait := p.Backoff << min(attempt-1, 30)
+	if p.MaxBackoff > 0 {
+		wait = min(wait, p.MaxBackoff)
+	}
+	return wait, true
+}
+
+// WithRetry makes database operations on conn retry according to policy when they fail with one of the transient
+// error codes it lists (by default TransientErrors), waiting between attempts with exponential backoff. Other errors
+// are returned at once, as is the last transient error once policy.MaxAttempts attempts have failed.
+// The operations retried are Get, Set, Increment, Delete, DeleteTree and Data, the other reads of a node's value
+// (ReadChunks, ValueLen, GetCapped and ValueEquals), and functions built on them. Iteration (SubscriptNext(),
+// NodeNext() and the iterators built on them) and Touch() are not retried; use Retry() to apply the policy to them
+// and to other operations. Operations within a transaction are not retried, since YottaDB
+// handles transient conditions there by restarting the transaction. A zero RetryPolicy turns retrying off.
+func (conn *Conn) WithRetry(policy RetryPolicy) {
+	conn.retryPolicy = nil
+	if policy.MaxAttempts > 1 {
+		conn.retryPolicy = &policy
+	}
+}
+
+// Retry calls fn, and calls it again according to the policy set by WithRetry() for as long as it returns a YDBError
+// with a transient error code. It returns the result of the last call.
+func (conn *Conn) Retry(fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		var ydbErr *YDBError
+		if err == nil || !errors.As(err, &ydbErr) || !conn.retrying(C.int(ydbErr.code), attempt) {
+			return err
+		}
+	}
+}
+
+// retrying reports whether a database operation on conn that returned ret on attempt number attempt should be retried,
+// first waiting for the backoff if so.
+func (conn *Conn) retrying(ret C.int, attempt int) bool {
+	if ret == C.YDB_OK || conn.retryPolicy == nil || conn.tpDepth > 0 {
+		return false
+	}
+	wait, ok := conn.retryPolicy.retryWait(int(ret), attempt)
+	if ok {
+		time.Sleep(wait)
+	}
+	return ok
+}
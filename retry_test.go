@@ -0,0 +1,112 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// Test that operations are retried after transient errors, with backoff, but not after other errors.
+func TestRetry(t *testing.T) {
+	conn := NewConn()
+	conn.WithRetry(RetryPolicy{MaxAttempts: 4, Backoff: time.Millisecond, MaxBackoff: 2 * time.Millisecond})
+	n := conn.Node("^retrypolicy")
+
+	// Inject two transient failures before the operation succeeds
+	calls := 0
+	start := time.Now()
+	err := conn.Retry(func() error {
+		calls++
+		if calls <= 2 {
+			return Error(YDB_ERR_TPRETRY, "%YDB-E-TPRETRY")
+		}
+		return n.Set("done")
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("got error %v after %d calls, want success after 3", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed < 3*time.Millisecond {
+		t.Errorf("got retries after %v, want backoff of at least 3ms", elapsed)
+	}
+	if val, err := n.Get(); err != nil || val != "done" {
+		t.Errorf("got %q, %v, want %q", val, err, "done")
+	}
+
+	// A transient error that persists is returned after MaxAttempts attempts
+	calls = 0
+	err = conn.Retry(func() error { calls++; return Error(YDB_ERR_LOCKSPACEFULL, "%YDB-E-LOCKSPACEFULL") })
+	if !errors.Is(err, Error(YDB_ERR_LOCKSPACEFULL, "")) || calls != 4 {
+		t.Errorf("got error %v after %d calls, want LOCKSPACEFULL after 4", err, calls)
+	}
+
+	// Other errors pass through at once
+	calls = 0
+	err = conn.Retry(func() error { calls++; return Error(YDB_ERR_GVUNDEF, "%YDB-E-GVUNDEF") })
+	if calls != 1 || err == nil {
+		t.Errorf("got error %v after %d calls, want GVUNDEF after 1", err, calls)
+	}
+	n.Delete()
+	if _, err := n.Get(); !isUndef(err) {
+		t.Errorf("got error %v, want GVUNDEF", err)
+	}
+
+	// Custom codes replace TransientErrors, and a zero policy turns retrying off
+	policy := RetryPolicy{MaxAttempts: 3, Codes: []int{YDB_ERR_GVUNDEF}}
+	if _, ok := policy.retryWait(YDB_ERR_TPRETRY, 1); ok {
+		t.Errorf("got TPRETRY retried by a policy that does not list it")
+	}
+	if _, ok := policy.retryWait(YDB_ERR_GVUNDEF, 2); !ok {
+		t.Errorf("got GVUNDEF not retried by a policy that lists it")
+	}
+
+	// Database operations themselves are retried: Get of an undefined node waits for the backoff before each retry, so
+	// a value set meanwhile from another connection is returned
+	conn.WithRetry(RetryPolicy{MaxAttempts: 3, Backoff: 10 * time.Millisecond, Codes: []int{YDB_ERR_GVUNDEF}})
+	start = time.Now()
+	if _, err := n.Get(); !isUndef(err) {
+		t.Errorf("got error %v, want GVUNDEF", err)
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("got GVUNDEF after %v, want backoff of at least 30ms", elapsed)
+	}
+	go func() {
+		time.Sleep(time.Millisecond)
+		NewConn().Node("^retrypolicy").Set("late")
+	}()
+	if val, err := n.Get(); err != nil || val != "late" {
+		t.Errorf("got %q, %v, want %q", val, err, "late")
+	}
+	// So are the other reads of a node's value
+	n.Delete()
+	reads := map[string]func(){
+		"ReadChunks":  func() { n.ReadChunks(10, func([]byte) error { return nil }) },
+		"ValueLen":    func() { n.ValueLen() },
+		"GetCapped":   func() { n.GetCapped(10) },
+		"ValueEquals": func() { n.ValueEquals(nil) },
+	}
+	for name, read := range reads {
+		start = time.Now()
+		read()
+		if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+			t.Errorf("got %s of an undefined node after %v, want backoff of at least 30ms", name, elapsed)
+		}
+	}
+	conn.WithRetry(RetryPolicy{})
+	calls = 0
+	conn.Retry(func() error { calls++; return Error(YDB_ERR_TPRETRY, "%YDB-E-TPRETRY") })
+	if calls != 1 {
+		t.Errorf("got %d calls with retrying off, want 1", calls)
+	}
+}
@@ -0,0 +1,190 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Implement reader-writer locks on top of YottaDB locks
+
+package yottadb
+
+import (
+	"sync"
+	"time"
+)
+
+// YottaDB locks are exclusive, so RLock() and WLock() build shared and exclusive locking of a node n from:
+//   - a write-intent lock on n(MetaSubscript,"rwlock"), which a writer holds for as long as it has the write lock
+//     and a reader holds only briefly while it registers itself;
+//   - a reader count stored in n(MetaSubscript,"readers"), which counts the processes that hold read locks on n.
+// A writer first takes the write-intent lock, which stops new readers, then waits for the reader count to fall to zero.
+// Since YottaDB locks belong to the process, goroutines within a process are coordinated by rwLocks instead, so each
+// process counts just once in the database however many of its goroutines hold the read lock. A goroutine waiting to
+// write is also recorded there so that new readers in its process wait too, since they do not need the write-intent
+// lock while other goroutines of the process hold the read lock.
+
+// rwPollInterval is how often RLock() and WLock() retry while waiting for a lock.
+const rwPollInterval = time.Millisecond
+
+// rwState records which reader-writer locks are held by goroutines of this process.
+type rwState struct {
+	readers int  // number of read locks held in this process
+	writer  bool // whether the write lock (or the write-intent lock while waiting for readers) is held in this process
+	waiting int  // number of goroutines in this process waiting to take the write-intent lock
+}
+
+// rwLocks holds the reader-writer lock state of each node, keyed by Node.String() like lockCounts.
+// Its mutex is held while registering with the database, which never waits, so that goroutines take turns to do so.
+var rwLocks = struct {
+	sync.Mutex
+	m map[string]*rwState
+}{m: make(map[string]*rwState)}
+
+// rwTry repeatedly calls try, with rwLocks locked and the state of n, until it returns true or an error, or until
+// deadline passes (unless forever is set), in which case it returns ErrLockTimeout.
+func (n *Node) rwTry(deadline time.Time, forever bool, try func(st *rwState) (bool, error)) error {
+	for {
+		rwLocks.Lock()
+		key := n.String()
+		st := rwLocks.m[key]
+		if st == nil {
+			st = &rwState{}
+			rwLocks.m[key] = st
+		}
+		ok, err := try(st)
+		if *st == (rwState{}) {
+			delete(rwLocks.m, key)
+		}
+		rwLocks.Unlock()
+		if ok || err != nil {
+			return err
+		}
+		if !forever && time.Now().After(deadline) {
+			return ErrLockTimeout
+		}
+		time.Sleep(rwPollInterval)
+	}
+}
+
+// RLock acquires a shared read lock on n, waiting while another goroutine or process holds the write lock from WLock().
+// Any number of readers may hold the read lock at once. The timeout behaves as for Lock(); on timeout RLock returns
+// ErrLockTimeout. Release the lock with RUnlock().
+// Reader-writer locks are advisory and are built from YottaDB locks and a reader count stored under
+// n(MetaSubscript,...), so they only exclude participants that also use RLock() and WLock(), and n should be a global
+// node for them to work between processes. A process that exits without calling RUnlock() leaves the reader count
+// too high, which makes writers wait until the count is corrected.
+func (n *Node) RLock(timeout ...time.Duration) error {
+	wait, forever := n.conn.lockWait(timeout)
+	gate := n.Child(MetaSubscript, "rwlock")
+	return n.rwTry(time.Now().Add(wait), forever, func(st *rwState) (bool, error) {
+		if st.writer || st.waiting > 0 {
+			return false, nil // give way to writers so that they are not starved
+		}
+		if st.readers == 0 {
+			// First reader in this process: register in the database unless another process has write intent
+			if err := gate.Lock(0); err == ErrLockTimeout {
+				return false, nil
+			} else if err != nil {
+				return false, err
+			}
+			_, err := n.Child(MetaSubscript, "readers").Increment("1")
+			gate.Unlock()
+			if err != nil {
+				return false, err
+			}
+		}
+		st.readers++
+		return true, nil
+	})
+}
+
+// RUnlock releases a read lock on n acquired by RLock().
+func (n *Node) RUnlock() error {
+	rwLocks.Lock()
+	defer rwLocks.Unlock()
+	key := n.String()
+	st := rwLocks.m[key]
+	if st == nil || st.readers == 0 {
+		return nil
+	}
+	st.readers--
+	if st.readers > 0 {
+		return nil
+	}
+	if *st == (rwState{}) {
+		delete(rwLocks.m, key)
+	}
+	_, err := n.Child(MetaSubscript, "readers").Increment("-1")
+	return err
+}
+
+// WLock acquires an exclusive write lock on n, waiting until no other goroutine or process holds the read or write
+// lock. A waiting writer stops new readers from acquiring the read lock so that writers are not starved by a stream of
+// overlapping readers.
+// The timeout behaves as for Lock(); on timeout WLock returns ErrLockTimeout. Release the lock with WUnlock().
+// See RLock() for the conditions under which reader-writer locks are effective.
+func (n *Node) WLock(timeout ...time.Duration) error {
+	wait, forever := n.conn.lockWait(timeout)
+	deadline := time.Now().Add(wait)
+	gate := n.Child(MetaSubscript, "rwlock")
+	waiting := false
+	err := n.rwTry(deadline, forever, func(st *rwState) (bool, error) {
+		if !waiting {
+			st.waiting++
+			waiting = true
+		}
+		if st.writer {
+			return false, nil
+		}
+		if err := gate.Lock(0); err == ErrLockTimeout {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		st.waiting--
+		waiting = false
+		st.writer = true
+		return true, nil
+	})
+	if waiting {
+		n.rwTry(deadline, true, func(st *rwState) (bool, error) {
+			st.waiting--
+			return true, nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	// Now wait for readers to finish, including any in this process; new readers are held back by the write-intent lock
+	readers := n.Child(MetaSubscript, "readers")
+	err = n.rwTry(deadline, forever, func(st *rwState) (bool, error) {
+		count, err := readers.Get("0")
+		return count == "0", err
+	})
+	if err != nil {
+		n.WUnlock()
+	}
+	return err
+}
+
+// WUnlock releases a write lock on n acquired by WLock().
+func (n *Node) WUnlock() error {
+	rwLocks.Lock()
+	defer rwLocks.Unlock()
+	key := n.String()
+	st := rwLocks.m[key]
+	if st == nil || !st.writer {
+		return nil
+	}
+	st.writer = false
+	if *st == (rwState{}) {
+		delete(rwLocks.m, key)
+	}
+	return n.Child(MetaSubscript, "rwlock").Unlock()
+}
@@ -0,0 +1,130 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// Test that reader-writer locks allow multiple readers but exclusive writers.
+func TestRWLock(t *testing.T) {
+	NewConn().Node("^rwlock").DeleteTree()
+	var readers, writers, maxReaders atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := NewConn().Node("^rwlock")
+			for range 20 {
+				if i%4 == 0 {
+					if err := n.WLock(); err != nil {
+						t.Error(err)
+						return
+					}
+					if w := writers.Add(1); w != 1 || readers.Load() != 0 {
+						t.Errorf("got %d writers and %d readers while holding write lock", w, readers.Load())
+					}
+					time.Sleep(100 * time.Microsecond)
+					writers.Add(-1)
+					n.WUnlock()
+				} else {
+					if err := n.RLock(); err != nil {
+						t.Error(err)
+						return
+					}
+					r := readers.Add(1)
+					if writers.Load() != 0 {
+						t.Errorf("got a writer while holding read lock")
+					}
+					if r > maxReaders.Load() {
+						maxReaders.Store(r)
+					}
+					time.Sleep(time.Millisecond)
+					readers.Add(-1)
+					n.RUnlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if maxReaders.Load() < 2 {
+		t.Errorf("got at most %d concurrent readers, want several", maxReaders.Load())
+	}
+	n := NewConn().Node("^rwlock")
+	if count, _ := n.Child(MetaSubscript, "readers").Get("0"); count != "0" {
+		t.Errorf("got reader count %s after all readers finished, want 0", count)
+	}
+
+	// Simulate a reader in another process
+	n.Child(MetaSubscript, "readers").Set("1")
+	if err := n.WLock(20 * time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("got error %v, want %v", err, ErrLockTimeout)
+	}
+	if err := n.RLock(0); err != nil {
+		t.Errorf("got error %v getting read lock, want none", err)
+	}
+	n.RUnlock()
+	n.Child(MetaSubscript, "readers").Set("0")
+	if err := n.WLock(0); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.RLock(10 * time.Millisecond); !errors.Is(err, ErrLockTimeout) {
+		t.Errorf("got error %v, want %v", err, ErrLockTimeout)
+	}
+	n.WUnlock()
+}
+
+// Test that a writer gets the lock while overlapping readers in the same process keep arriving.
+func TestRWLockWriterNotStarved(t *testing.T) {
+	NewConn().Node("^rwstarve").DeleteTree()
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := NewConn().Node("^rwstarve")
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if err := n.RLock(time.Second); err != nil {
+					t.Error(err)
+					return
+				}
+				time.Sleep(2 * time.Millisecond) // readers overlap, so some reader always holds the lock
+				n.RUnlock()
+			}
+		}()
+	}
+	time.Sleep(10 * time.Millisecond)
+	n := NewConn().Node("^rwstarve")
+	err := n.WLock(500 * time.Millisecond)
+	close(stop)
+	if err != nil {
+		t.Errorf("got error %v, want the writer to get the lock despite arriving readers", err)
+	} else {
+		n.WUnlock()
+	}
+	wg.Wait()
+	if count, _ := n.Child(MetaSubscript, "readers").Get("0"); count != "0" {
+		t.Errorf("got reader count %s after all readers finished, want 0", count)
+	}
+}
@@ -0,0 +1,93 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Define a connection that may be safely shared between goroutines
+
+package yottadb
+
+import (
+	"sync"
+)
+
+// SyncConn wraps a Conn so that it can be shared safely by multiple goroutines.
+// A Conn and the Nodes it creates share buffers for passing values to and from YottaDB, so they may only be used by
+// one goroutine at a time. SyncConn serialises all operations through a mutex instead, so goroutines that share it
+// take turns to access the database.
+//
+// This trades throughput for simplicity: goroutines sharing a SyncConn never run database operations concurrently.
+// Where throughput matters, give each goroutine its own Conn instead so that their database operations run in parallel.
+type SyncConn struct {
+	mu   sync.Mutex
+	conn *Conn
+}
+
+// NewSyncConn creates a new connection that may be shared by multiple goroutines.
+func NewSyncConn() *SyncConn {
+	return &SyncConn{conn: NewConn()}
+}
+
+// Node creates a Node on the underlying connection.
+// The returned Node must only be accessed via the methods of SyncConn (or within Do()) because the Node's own methods
+// would use the underlying connection without holding the SyncConn's mutex.
+func (s *SyncConn) Node(varname string, subscripts ...string) *Node {
+	return s.conn.Node(varname, subscripts...)
+}
+
+// Do runs fn with exclusive use of the underlying connection, so that fn may use any Conn or Node method on it,
+// including several operations in sequence without other goroutines intervening.
+func (s *SyncConn) Do(fn func(*Conn) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return fn(s.conn)
+}
+
+// Transaction runs fn within a transaction using the underlying connection. See Conn.Transaction().
+func (s *SyncConn) Transaction(fn func(*Conn) error, validate ...func() error) error {
+	return s.Do(func(conn *Conn) error {
+		return conn.Transaction(fn, validate...)
+	})
+}
+
+// Set the value of node n, which must have been created by s.Node(). See Node.Set().
+func (s *SyncConn) Set(n *Node, val string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.Set(val)
+}
+
+// Get the value of node n, which must have been created by s.Node(). See Node.Get().
+func (s *SyncConn) Get(n *Node, deflt ...string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.Get(deflt...)
+}
+
+// Data returns whether node n has a value and/or subtree; n must have been created by s.Node(). See Node.Data().
+func (s *SyncConn) Data(n *Node) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.Data()
+}
+
+// Delete the value of node n, which must have been created by s.Node(). See Node.Delete().
+func (s *SyncConn) Delete(n *Node) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.Delete()
+}
+
+// DeleteTree deletes the value and subtree of node n, which must have been created by s.Node(). See Node.DeleteTree().
+func (s *SyncConn) DeleteTree(n *Node) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return n.DeleteTree()
+}
@@ -0,0 +1,74 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// Test many goroutines hammering a shared SyncConn. Run with -race to check for data races.
+func TestSyncConn(t *testing.T) {
+	const goroutines, iterations = 20, 200
+	s := NewSyncConn()
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := s.Node("^syncconn", strconv.Itoa(g))
+			for i := range iterations {
+				val := strconv.Itoa(g*iterations + i)
+				if err := s.Set(n, val); err != nil {
+					t.Error(err)
+					return
+				}
+				got, err := s.Get(n)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got != val {
+					t.Errorf("got %s, want %s", got, val)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	total := 0
+	err := s.Transaction(func(conn *Conn) error {
+		total = 0
+		for g := range goroutines {
+			val, err := conn.Node("^syncconn", strconv.Itoa(g)).Get()
+			if err != nil {
+				return err
+			}
+			n, _ := strconv.Atoi(val)
+			total += n
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := 0
+	for g := range goroutines {
+		want += g*iterations + iterations - 1
+	}
+	if total != want {
+		t.Errorf("got total %d, want %d", total, want)
+	}
+}
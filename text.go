@@ -0,0 +1,47 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Store and check values that are meant to be UTF-8 text.
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"unicode/utf8"
+)
+
+// ErrInvalidUTF8 is returned by SetString() when the value is not valid UTF-8.
+var ErrInvalidUTF8 = errors.New("YDB: value is not valid UTF-8")
+
+// Values are bytes: Set() stores the bytes of a Go string exactly as they are, and Get() returns the stored bytes as a
+// Go string, whether or not they are valid UTF-8. This suits binary data, but where a value is meant to be text, the
+// following functions check the boundary between Go strings and YottaDB bytes.
+
+// SetString sets the value of n to the UTF-8 bytes of s, like Set(), but first checks that s is valid UTF-8 and
+// returns an error wrapping ErrInvalidUTF8 if it is not, so that text values are never stored as invalid UTF-8.
+func (n *Node) SetString(s string) error {
+	if !utf8.ValidString(s) {
+		return fmt.Errorf("%w: %q for %v", ErrInvalidUTF8, s, n)
+	}
+	return n.Set(s)
+}
+
+// GetValidUTF8 returns the value of n like Get(), and reports whether it is valid UTF-8, such as to detect text that
+// was stored by code that does not use SetString(). The value is returned unchanged even if it is not valid UTF-8.
+func (n *Node) GetValidUTF8() (string, bool, error) {
+	val, err := n.Get()
+	if err != nil {
+		return val, false, err
+	}
+	return val, utf8.ValidString(val), nil
+}
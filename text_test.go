@@ -0,0 +1,48 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"testing"
+)
+
+// Test storing UTF-8 text with SetString and checking stored values with GetValidUTF8.
+func TestUTF8(t *testing.T) {
+	n := NewConn().Node("^utf8")
+	for _, s := range []string{"plain", "héllo, 世界", ""} {
+		if err := n.SetString(s); err != nil {
+			t.Fatal(err)
+		}
+		if val, valid, err := n.GetValidUTF8(); err != nil || val != s || !valid {
+			t.Errorf("got %q, %v, %v; want %q, true, nil", val, valid, err, s)
+		}
+	}
+
+	invalid := "bad\xff\xfe"
+	if err := n.SetString(invalid); !errors.Is(err, ErrInvalidUTF8) {
+		t.Errorf("got error %v, want ErrInvalidUTF8", err)
+	}
+	if val, _ := n.Get(); val != "" {
+		t.Errorf("got %q, want the value to be unchanged", val)
+	}
+	n.Set(invalid) // binary values may still be stored with Set
+	if val, valid, err := n.GetValidUTF8(); err != nil || val != invalid || valid {
+		t.Errorf("got %q, %v, %v; want %q, false, nil", val, valid, err, invalid)
+	}
+
+	n.Delete()
+	if _, valid, err := n.GetValidUTF8(); !isUndef(err) || valid {
+		t.Errorf("got %v, error %v; want false and GVUNDEF", valid, err)
+	}
+}
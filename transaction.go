@@ -0,0 +1,672 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Implement YottaDB transactions (TP) using ydb_tp_st()
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"runtime/cgo"
+	"strconv"
+	"strings"
+	"time"
+	"unsafe"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+extern int tpCallbackWrapper(uint64_t tptoken, ydb_buffer_t *errstr, void *tpfnparm);
+*/
+import "C"
+
+// ErrRestart may be returned by a transaction callback to make YottaDB restart the transaction.
+// It is also the error returned by database operations within a transaction when YottaDB detects a conflict that
+// requires the transaction to restart, so callbacks should pass it back out to Transaction() unchanged.
+var ErrRestart error = &YDBError{int(C.YDB_TP_RESTART), "YDB: transaction restart"}
+
+// ErrRollback may be returned by a transaction callback to roll back the transaction without raising any other error.
+// Transaction() returns ErrRollback when a transaction was rolled back.
+var ErrRollback error = &YDBError{int(C.YDB_TP_ROLLBACK), "YDB: transaction rolled back"}
+
+// ErrTPTooDeep is returned by Transaction() if starting the transaction would nest transactions more than MaxTPDepth deep.
+var ErrTPTooDeep = errors.New("YDB: transactions nested more than MaxTPDepth deep")
+
+// MaxTPDepth is the maximum depth to which YottaDB allows transactions to be nested (the maximum value of $TLEVEL).
+// Transaction() checks it before calling YottaDB so that runaway recursion produces the clear error ErrTPTooDeep.
+const MaxTPDepth = 127
+
+// NOTTP is the tptoken that YottaDB API calls use outside a transaction, as returned by Conn.TPToken().
+const NOTTP uint64 = C.YDB_NOTTP
+
+// ErrNotInTransaction is returned by Savepoint() and TxLock() when they are called outside a transaction.
+var ErrNotInTransaction = errors.New("YDB: not in a transaction")
+
+// errProbeWrite is returned by database writes attempted while Transaction() is running fn outside TP to see whether
+// it is read-only. It causes Transaction() to run fn again within a real transaction, so it is never returned to the caller.
+var errProbeWrite = errors.New("YDB: database write while probing for a read-only transaction")
+
+// tpInfo holds the state of one call to Transaction() for use by the C callback wrapper.
+type tpInfo struct {
+	conn     *Conn
+	fn       func(*Conn) error
+	parent   *tpInfo // the enclosing transaction, if this one is nested
+	err      error   // error returned by fn that caused a rollback, to be returned by Transaction()
+	attempts int     // number of times fn has been called, including restarts
+	restarts []RestartReason
+	panicked bool              // whether fn panicked, in which case the transaction was rolled back
+	panicVal any               // the value fn panicked with, to re-panic with once ydb_tp_st() has returned
+	deferred []func()          // functions registered by Defer() during the current attempt, to run after commit
+	changed  NodeSet           // nodes updated during the current attempt, recorded if conn has an OnCommit() handler
+	written  map[string]string // values set during the current attempt, keyed by Node.Key(), if conn.verifyReads is set
+	locks    []*Node           // nodes locked by TxLock() during the current attempt, to unlock when it ends
+}
+
+// RestartReason classifies why a transaction restarted.
+type RestartReason int
+
+const (
+	RestartNone     RestartReason = iota // no restart
+	RestartManual                        // the callback returned ErrRestart itself to request a restart
+	RestartConflict                      // YottaDB detected that another process updated data the transaction used
+)
+
+// String returns the name of a RestartReason.
+func (r RestartReason) String() string {
+	switch r {
+	case RestartNone:
+		return "none"
+	case RestartManual:
+		return "manual"
+	case RestartConflict:
+		return "conflict"
+	}
+	return "unknown"
+}
+
+// TxStats describes the most recently completed transaction on a connection, as returned by Conn.TxStats().
+type TxStats struct {
+	Attempts int             // number of times the callback was called
+	Restarts []RestartReason // reason for each restart, in order; its length is Attempts-1
+}
+
+// TxStats returns statistics about the most recently completed call to Transaction() or Snapshot() on conn,
+// including the reason for each restart. A conflict detected by YottaDB within a database operation or at commit is
+// reported as RestartConflict. Note that a transaction that exceeds its time limit ($ZMAXTPTIME) does not restart:
+// Transaction() returns the YottaDB error YDB_ERR_TPTIMEOUT instead.
+func (conn *Conn) TxStats() TxStats {
+	return conn.txStats
+}
+
+// ProcessInfo describes the state of the YottaDB engine in the current process, as returned by Conn.ProcessInfo().
+type ProcessInfo struct {
+	PID             int    // process id ($JOB)
+	TLevel          int    // transaction nesting level ($TLEVEL): 0 outside a transaction
+	TRestart        int    // restarts of the current transaction ($TRESTART): 0 outside a transaction
+	GlobalDirectory string // global directory that maps globals to database regions ($ZGBLDIR)
+	Release         string // YottaDB release ($ZYRELEASE)
+}
+
+// ProcessInfo returns the current state of the YottaDB engine, read from intrinsic special variables, for diagnostics.
+// It complements TxStats(), which describes a completed transaction, by showing whether a transaction is in progress.
+// The state belongs to the process, so it reflects transactions run by any Conn. The state of regions and their
+// journals is not included because YottaDB only reports it with $VIEW(), which is not available through the SimpleAPI.
+func (conn *Conn) ProcessInfo() (ProcessInfo, error) {
+	var info ProcessInfo
+	ints := []struct {
+		isv   string
+		value *int
+	}{{"$JOB", &info.PID}, {"$TLEVEL", &info.TLevel}, {"$TRESTART", &info.TRestart}}
+	for _, field := range ints {
+		val, err := conn.Node(field.isv).Get()
+		if err != nil {
+			return ProcessInfo{}, err
+		}
+		if *field.value, err = strconv.Atoi(val); err != nil {
+			return ProcessInfo{}, fmt.Errorf("YDB: invalid %s %q: %w", field.isv, val, err)
+		}
+	}
+	var err error
+	if info.GlobalDirectory, err = conn.Node("$ZGBLDIR").Get(); err != nil {
+		return ProcessInfo{}, err
+	}
+	if info.Release, err = conn.Node("$ZYRELEASE").Get(); err != nil {
+		return ProcessInfo{}, err
+	}
+	return info, nil
+}
+
+// restartReason classifies err, returned by a transaction callback, as the reason for a restart or RestartNone.
+func restartReason(err error) RestartReason {
+	var ydbErr *YDBError
+	switch {
+	case !errors.As(err, &ydbErr) || ydbErr.code != int(C.YDB_TP_RESTART):
+		return RestartNone
+	case ydbErr == ErrRestart:
+		return RestartManual
+	}
+	return RestartConflict // returned by a database operation
+}
+
+// Transaction runs fn within a YottaDB transaction using ydb_tp_st().
+// All database operations done by fn using conn (or nodes created by conn) form part of the transaction, and are
+// committed atomically when fn returns nil.
+// If fn returns ErrRestart (or passes on the ErrRestart returned by a database operation) YottaDB rolls back
+// the transaction and calls fn again. This also happens if YottaDB detects at commit time that another process has
+// updated globals that fn read. Because fn may therefore run more than once, it must not have side effects other than
+// on the database, and it should (re)initialise any result variables it captures each time it is called.
+// If fn returns any other error the transaction is rolled back and Transaction returns that error.
+// If fn panics, the transaction is rolled back and Transaction panics with the same value, except that a panic with an
+// error matching ErrRestart restarts the transaction like returning it. This is how the iterators, which panic on
+// YottaDB errors, pass on the YDB_TP_RESTART that a database operation returns when YottaDB detects a conflict.
+// Transactions may be nested by calling Transaction again from within fn, up to MaxTPDepth deep.
+// See SetSkipReadOnlyTP() to avoid the overhead of TP for callbacks that turn out to make no database updates.
+//
+// If validate is supplied, validate[0] is called after fn returns nil, just before the transaction commits, as a last
+// check of any invariants that the database must satisfy. It runs within the transaction, like fn, and its result is
+// treated like that of fn: returning an error rolls back the transaction and Transaction returns that error.
+// This keeps checks of invariants separate from the updates made by fn.
+func (conn *Conn) Transaction(fn func(*Conn) error, validate ...func() error) error {
+	if len(validate) > 0 {
+		update, check := fn, validate[0]
+		fn = func(conn *Conn) error {
+			if err := update(conn); err != nil {
+				return err
+			}
+			return check()
+		}
+	}
+	if conn.probing {
+		// Nested within a probe: any write will abort the outermost probe, so just run fn
+		return fn(conn)
+	}
+	if conn.skipTP && conn.tpDepth == 0 {
+		probe := tpInfo{conn: conn}
+		conn.probing, conn.wrote, conn.tx = true, false, &probe
+		err := func() error {
+			defer func() { conn.probing, conn.tx = false, nil }()
+			return fn(conn)
+		}()
+		if !conn.wrote && !errors.Is(err, ErrRestart) {
+			if err == nil {
+				probe.runDeferred()
+			}
+			return err
+		}
+		// fn tried to update the database (or asked to restart) so run it properly within TP
+	}
+	return conn.transaction(fn)
+}
+
+// transaction implements Transaction() by always running fn within TP.
+func (conn *Conn) transaction(fn func(*Conn) error) error {
+	if conn.tpDepth >= MaxTPDepth {
+		return ErrTPTooDeep
+	}
+	conn.tpDepth++
+	defer func() { conn.tpDepth-- }()
+	if metricsEnabled.Load() {
+		metrics.Transactions.Add(1)
+	}
+	info := tpInfo{conn: conn, fn: fn, parent: conn.tx}
+	handle := cgo.NewHandle(&info)
+	defer handle.Delete()
+	parent := info.parent
+	conn.tx = &info
+	cconn := conn.c
+	ret := C.ydb_tp_st(cconn.tptoken, &cconn.errstr, C.ydb_tp2fnptr_t(C.tpCallbackWrapper), unsafe.Pointer(&handle), nil, 0, nil)
+	conn.tx = parent
+	if ret == C.YDB_OK && parent != nil {
+		// Locks taken by a nested transaction are held until the outermost transaction ends
+		parent.locks = append(parent.locks, info.locks...)
+	} else {
+		info.unlock()
+	}
+	conn.txStats = TxStats{Attempts: info.attempts, Restarts: info.restarts}
+	if ret != C.YDB_TP_RESTART {
+		// Keep the reason for a restart being passed on to an enclosing transaction
+		conn.restartReason = RestartNone
+	}
+	if info.panicked {
+		panic(info.panicVal)
+	}
+	if info.err != nil {
+		return info.err
+	}
+	if ret == C.YDB_OK {
+		if parent != nil {
+			// A nested transaction is only committed when the outermost transaction commits
+			parent.deferred = append(parent.deferred, info.deferred...)
+			for _, node := range info.changed.Nodes() {
+				parent.changed.Add(node)
+			}
+			for key, val := range info.written {
+				parent.noteWrite(key, val)
+			}
+		} else {
+			if conn.onCommit != nil && info.changed.Len() > 0 {
+				conn.onCommit(info.changed.Nodes())
+			}
+			info.runDeferred()
+		}
+	}
+	switch ret {
+	case C.YDB_TP_RESTART:
+		return ErrRestart
+	case C.YDB_TP_ROLLBACK:
+		return ErrRollback
+	}
+	return conn.Error(ret)
+}
+
+// tpCallbackWrapper is called by ydb_tp_st() to run the Go function passed to Transaction().
+// It switches the connection to the transaction's tptoken while fn runs so that all operations on conn
+// form part of the transaction.
+// A panic cannot unwind through ydb_tp_st()'s C stack frames, so if fn panics the wrapper recovers, makes YottaDB roll
+// back the transaction, and leaves transaction() to re-panic with the same value once ydb_tp_st() has returned.
+// A panic with an error matching ErrRestart instead makes YottaDB restart the transaction.
+//
+//export tpCallbackWrapper
+func tpCallbackWrapper(tptoken C.uint64_t, errstr *C.ydb_buffer_t, tpfnparm unsafe.Pointer) (ret C.int) {
+	info := (*(*cgo.Handle)(tpfnparm)).Value().(*tpInfo)
+	cconn := info.conn.c
+	saved := cconn.tptoken
+	cconn.tptoken = tptoken
+	defer func() { cconn.tptoken = saved }()
+	defer func() {
+		if r := recover(); r != nil {
+			if err, ok := r.(error); ok && errors.Is(err, ErrRestart) {
+				if info.conn.restartReason == RestartNone {
+					info.conn.restartReason = restartReason(err)
+				}
+				ret = C.YDB_TP_RESTART
+				return
+			}
+			info.panicked, info.panicVal = true, r
+			ret = C.YDB_TP_ROLLBACK
+		}
+	}()
+
+	info.unlock() // release locks taken by an attempt that restarted
+	info.err, info.deferred, info.changed, info.written = nil, nil, NodeSet{}, nil
+	info.attempts++
+	if info.attempts > 1 {
+		reason := info.conn.restartReason
+		if reason == RestartNone {
+			// fn returned nil, so the restart was due to a conflict detected at commit
+			reason = RestartConflict
+		}
+		info.restarts = append(info.restarts, reason)
+		info.conn.restartReason = RestartNone
+		if info.conn.onRestart != nil {
+			info.conn.onRestart(info.attempts-1, reason.String())
+		}
+		if metricsEnabled.Load() {
+			metrics.Restarts.Add(1)
+		}
+	}
+	err := info.fn(info.conn)
+	if info.conn.restartReason == RestartNone {
+		// A nested transaction may already have recorded the reason for a restart that it passed on
+		info.conn.restartReason = restartReason(err)
+	}
+	switch {
+	case err == nil:
+		return C.YDB_OK
+	case errors.Is(err, ErrRestart):
+		return C.YDB_TP_RESTART
+	case errors.Is(err, ErrRollback):
+		return C.YDB_TP_ROLLBACK
+	}
+	info.err = err
+	return C.YDB_TP_ROLLBACK
+}
+
+// Defer registers fn to run once the transaction that conn is running has committed, so that a transaction callback
+// can trigger side effects, such as publishing an event or logging, only if its updates are actually committed.
+// Deferred functions run in the order registered, after Transaction() has committed the outermost transaction and
+// before it returns. They do not run if the transaction is rolled back, and those registered before a restart are
+// discarded, so each attempt registers them afresh. A nested transaction's deferred functions run when the outermost
+// transaction commits, and are discarded if the nested transaction rolls back. Outside a transaction, fn runs at once.
+func (conn *Conn) Defer(fn func()) {
+	if conn.tx == nil {
+		fn()
+		return
+	}
+	conn.tx.deferred = append(conn.tx.deferred, fn)
+}
+
+// OnCommit sets fn to be called each time a transaction on conn commits, with the nodes that the transaction updated
+// using Set, Delete, DeleteTree or Increment (and functions built on them), in the order first updated. This supports
+// change-data-capture, such as publishing the keys that changed. fn is called after the outermost transaction commits
+// and before any functions registered with Defer(). Updates made in a nested transaction that rolled back, or in an
+// attempt that restarted, are not included, nor are updates made outside a transaction. Deleting a tree reports only
+// the node deleted, not its descendants. Nodes are only recorded while a handler is set; a nil fn removes it.
+func (conn *Conn) OnCommit(fn func(changed []*Node)) {
+	conn.onCommit = fn
+}
+
+// OnRestart sets fn to be called each time a transaction on conn restarts, before the callback is retried, so that
+// applications can log contention without reimplementing the retry loop of Transaction() or TransactionWithRetry().
+// attempt is the number of the attempt that restarted, counting from 1, and reason describes why, as given by
+// RestartReason.String(), such as "conflict". fn is called within the transaction, so it must not use conn.
+// A nil fn removes the handler.
+func (conn *Conn) OnRestart(fn func(attempt int, reason string)) {
+	conn.onRestart = fn
+}
+
+// recordChange records that node n is being updated, if changes are being tracked for OnCommit().
+func (conn *Conn) recordChange(n *Node) {
+	if conn.onCommit != nil && conn.tx != nil {
+		conn.tx.changed.Add(n)
+	}
+}
+
+// SetVerifyReads enables or disables an assertion mode that checks that transactions on conn read their own writes:
+// that within a transaction, getting a node returns the value most recently set (or incremented) in the same
+// transaction, before it commits. YottaDB guarantees this as long as every operation passes the transaction's tptoken,
+// so a read that does not see the transaction's own write indicates a bug in this wrapper, and Get panics with
+// ErrReadYourWrites. This is intended for tests, as it costs a map update for each write within a transaction.
+// Writes are not checked when a Node from another Conn is used within the transaction, and application triggers that
+// change values as they are set will also cause the check to fail.
+func (conn *Conn) SetVerifyReads(enable bool) {
+	conn.verifyReads = enable
+}
+
+// ErrReadYourWrites is the value that Get panics with, wrapped with details, if SetVerifyReads() is enabled and a
+// read within a transaction does not return the value that the same transaction wrote.
+var ErrReadYourWrites = errors.New("YDB: transaction did not read its own write")
+
+// recordWrite records, if read verification is enabled and conn is in a transaction, that n was set to val.
+func (conn *Conn) recordWrite(n *Node, val string) {
+	if conn.verifyReads && conn.tx != nil {
+		conn.tx.noteWrite(n.Key(), val)
+	}
+}
+
+// noteWrite records that the node with the given key was set to val during the current attempt of info.
+func (info *tpInfo) noteWrite(key, val string) {
+	if info.written == nil {
+		info.written = make(map[string]string)
+	}
+	info.written[key] = val
+}
+
+// forgetWrites discards any values recorded by recordWrite() for n, and also for its descendants if tree is set,
+// since they have been deleted. Recorded values are discarded from enclosing transactions too, which merely means that
+// fewer reads are checked if a nested transaction that deletes them then rolls back.
+func (conn *Conn) forgetWrites(n *Node, tree bool) {
+	if !conn.verifyReads {
+		return
+	}
+	key := n.Key()
+	for info := conn.tx; info != nil; info = info.parent {
+		delete(info.written, key)
+		if tree {
+			for k := range info.written {
+				if strings.HasPrefix(k, key+"\x00") {
+					delete(info.written, k)
+				}
+			}
+		}
+	}
+}
+
+// verifyRead panics with ErrReadYourWrites if read verification is enabled and the value of n read within the current
+// transaction, val, or the error err in reading it, does not match the value that the transaction last set n to.
+func (conn *Conn) verifyRead(n *Node, val string, err error) {
+	if !conn.verifyReads {
+		return
+	}
+	key := n.Key()
+	for info := conn.tx; info != nil; info = info.parent {
+		want, ok := info.written[key]
+		if !ok {
+			continue
+		}
+		if err != nil || val != want {
+			panic(fmt.Errorf("%w: %v read %q (error %v) after setting it to %q", ErrReadYourWrites, n, val, err, want))
+		}
+		return
+	}
+}
+
+// runDeferred runs the functions registered with Defer() for the transaction described by info.
+func (info *tpInfo) runDeferred() {
+	for _, fn := range info.deferred {
+		fn()
+	}
+}
+
+// InTransaction reports whether database operations on conn are currently part of a transaction, that is, whether it is
+// called from within a Transaction() or Snapshot() callback. It is false while Transaction() runs its callback outside
+// TP in case it is read-only (see SetSkipReadOnlyTP()).
+func (conn *Conn) InTransaction() bool {
+	return conn.tpDepth > 0
+}
+
+// TPToken returns the tptoken that conn currently passes to YottaDB API calls: NOTTP outside a transaction, or within a
+// transaction the token that YottaDB supplied to the callback. It is intended for debugging.
+func (conn *Conn) TPToken() uint64 {
+	return uint64(conn.c.tptoken)
+}
+
+// Savepoint runs fn as a nested transaction within the transaction that conn is currently running, so that if fn
+// returns an error, only the updates made by fn are rolled back. The enclosing transaction may then carry on and
+// commit its other updates, which suits "try this optional step, and ignore it if it fails" logic.
+// Savepoint returns the error from fn, or ErrNotInTransaction if conn is not running a transaction.
+// It is equivalent to calling Transaction() from within a transaction callback, which is how YottaDB nests transactions.
+// Note that if fn returns ErrRestart (perhaps from a database operation) it restarts the whole transaction, not just
+// the savepoint, so the enclosing callback must pass ErrRestart on rather than ignore it.
+func (conn *Conn) Savepoint(fn func() error) error {
+	if conn.tpDepth == 0 && !conn.probing {
+		return ErrNotInTransaction
+	}
+	return conn.Transaction(func(*Conn) error { return fn() })
+}
+
+// TxLock locks each of nodes, like Node.Lock(), for the rest of the transaction that conn is running, so that they are
+// released whether the transaction commits, rolls back or restarts. Locks taken by an attempt that restarts are released
+// before the next attempt, which locks them again as it runs, so the lock counts are the same on each attempt rather
+// than accumulating; and all are released when the outermost transaction ends. This avoids managing locks by hand
+// across restarts. A nested transaction's locks are released if it rolls back, and otherwise held until the outermost
+// transaction ends.
+// The timeout applies to each node in turn, as in Lock(). If a node cannot be locked, those locked by this call are
+// released and the error is returned. TxLock returns ErrNotInTransaction if conn is not running a transaction.
+func (conn *Conn) TxLock(timeout time.Duration, nodes ...*Node) error {
+	if conn.probing {
+		return conn.probeWrite()
+	}
+	if conn.tpDepth == 0 {
+		return ErrNotInTransaction
+	}
+	for i, n := range nodes {
+		n = n.on(conn)
+		if err := n.Lock(timeout); err != nil {
+			for _, locked := range conn.tx.locks[len(conn.tx.locks)-i:] {
+				locked.Unlock()
+			}
+			conn.tx.locks = conn.tx.locks[:len(conn.tx.locks)-i]
+			return err
+		}
+		conn.tx.locks = append(conn.tx.locks, n)
+	}
+	return nil
+}
+
+// unlock releases the locks taken by TxLock() for the transaction described by info.
+func (info *tpInfo) unlock() {
+	for _, n := range info.locks {
+		n.Unlock()
+	}
+	info.locks = nil
+}
+
+// OnceByKey runs fn within a transaction unless the node key already has a value, and sets key to mark that fn has run,
+// so that repeating a request with the same key does nothing: a common way to make request handlers idempotent.
+// The marker records when fn ran, in the format of SetTime(). Checking the marker, running fn and setting the marker
+// form a single transaction, so even when concurrent processes or goroutines submit the same key, fn commits its
+// updates just once. As with Transaction(), fn may be restarted, so it must not have side effects other than on the
+// database, which it should update using conn. If fn returns an error, the transaction is rolled back, no marker is
+// set, and OnceByKey returns that error. key should normally be a global node so that it persists.
+func (conn *Conn) OnceByKey(key *Node, fn func() error) error {
+	return conn.Transaction(func(conn *Conn) error {
+		marker := key.on(conn)
+		data, err := marker.Data()
+		if err != nil || data&1 != 0 {
+			return err
+		}
+		if err := fn(); err != nil {
+			return err
+		}
+		return marker.SetTime(time.Now())
+	})
+}
+
+// Snapshot runs fn with a consistent view of the database across all the reads that fn makes, which is a common
+// requirement of reports that read many related nodes.
+// It is simply Transaction() used for reading: YottaDB transactions are optimistic, so if another process updates any
+// global node that fn has read before fn returns, YottaDB restarts fn rather than let it see a mixture of old and new
+// values. The reads made by a successful fn therefore all reflect the database at a single point in time.
+// Return results from fn by assigning to variables captured by the closure. Since fn may be restarted, it must
+// reset those variables each time it is called rather than accumulate into them.
+// Unlike Transaction(), Snapshot always uses TP even if SetSkipReadOnlyTP() is enabled, since its purpose is consistency.
+func (conn *Conn) Snapshot(fn func(*Conn) error) error {
+	return conn.transaction(fn)
+}
+
+// ErrBudgetExceeded is returned by TransactionWithRetry() when the transaction is still restarting once its total time
+// budget has elapsed.
+var ErrBudgetExceeded = errors.New("YDB: transaction time budget exceeded")
+
+// TransactionWithRetry runs fn within a transaction like Transaction(), but limits how long it may take, which matters
+// for latency-sensitive services when a transaction keeps restarting due to contention.
+//   - If attemptTimeout > 0, YottaDB's time limit for the transaction, $ZMAXTPTIME, is set to attemptTimeout rounded up
+//     to whole seconds while the transaction runs; exceeding it returns the YottaDB error YDB_ERR_TPTIMEOUT.
+//     $ZMAXTPTIME applies to the whole process, and is restored to its previous value before TransactionWithRetry
+//     returns. YottaDB only applies it to the outermost transaction, so attemptTimeout has no effect when nested.
+//   - If budget > 0, then once budget has elapsed since TransactionWithRetry was called, any restart of fn is not
+//     attempted: the transaction is rolled back and an error wrapping ErrBudgetExceeded is returned instead.
+//     An attempt already running is not interrupted, so the call may take somewhat longer than budget.
+//
+// To log each restart, set a handler with OnRestart().
+func (conn *Conn) TransactionWithRetry(attemptTimeout, budget time.Duration, fn func(*Conn) error) (err error) {
+	if attemptTimeout > 0 {
+		isv := conn.Node("$ZMAXTPTIME")
+		saved, err := isv.Get()
+		if err != nil {
+			return err
+		}
+		seconds := int64((attemptTimeout + time.Second - 1) / time.Second)
+		if err := isv.setRaw(strconv.FormatInt(seconds, 10)); err != nil {
+			return err
+		}
+		defer func() {
+			if restoreErr := isv.setRaw(saved); err == nil {
+				err = restoreErr
+			}
+		}()
+	}
+	start := time.Now()
+	return conn.Transaction(func(conn *Conn) error {
+		// Count attempts made within TP, not any read-only probe run first because of SetSkipReadOnlyTP()
+		if attempts := conn.tx.attempts; budget > 0 && attempts > 1 && time.Since(start) > budget {
+			return fmt.Errorf("%w: %d attempts in %v", ErrBudgetExceeded, attempts-1, time.Since(start).Round(time.Millisecond))
+		}
+		return fn(conn)
+	})
+}
+
+// setRaw sets the value of n to val with ydb_set_st() directly, bypassing the recording, change tracking and retrying
+// done by Set(), for internal settings such as intrinsic special variables that are not application updates.
+func (n *Node) setRaw(val string) error {
+	c_n := n.n // access C.node from Go node
+	conn := c_n.conn
+	buf := C.ydb_buffer_t{buf_addr: C.CString(val), len_used: C.uint(len(val)), len_alloc: C.uint(len(val))}
+	defer C.free(unsafe.Pointer(buf.buf_addr))
+	ret := C.ydb_set_st(conn.tptoken, &conn.errstr, &c_n.buffers[0], c_n.len-1, (*C.ydb_buffer_t)(unsafe.Add(unsafe.Pointer(&c_n.buffers[0]), C.sizeof_ydb_buffer_t)), &buf)
+	return n.conn.Error(ret)
+}
+
+// WorkItem is one item of work for BatchedTransaction().
+type WorkItem = any
+
+// BatchedTransaction applies fn to each of items in turn, in separate transactions of perTx items each (the last may
+// have fewer), so that a large batch of writes does not make one oversized transaction. A transaction that updates
+// many nodes holds more state, takes longer, and is more likely to conflict with other processes, so it risks
+// repeated restarts or exceeding YottaDB's limits. Each transaction is run by Transaction(), so fn may be called again
+// for the items of a transaction that restarts.
+// If fn returns an error, that transaction is rolled back and BatchedTransaction returns the error, noting the index of
+// the first item in the failed transaction. The transactions before it remain committed, so items from that index on
+// may be passed to BatchedTransaction again to resume.
+func (conn *Conn) BatchedTransaction(items []WorkItem, perTx int, fn func(*Conn, WorkItem) error) error {
+	if perTx < 1 {
+		return fmt.Errorf("YDB: batch size %d is less than 1", perTx)
+	}
+	for start := 0; start < len(items); start += perTx {
+		batch := items[start:min(start+perTx, len(items))]
+		err := conn.Transaction(func(conn *Conn) error {
+			for _, item := range batch {
+				if err := fn(conn, item); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("%w (in the transaction starting at item %d)", err, start)
+		}
+	}
+	return nil
+}
+
+// errChecksPassed rolls back the transaction in which AssertConsistent() ran its checks once they have all passed.
+var errChecksPassed = errors.New("YDB: consistency checks passed")
+
+// AssertConsistent runs each of checks in turn within a single Snapshot(), so that together they verify invariants
+// across several globals, even in different regions, at one consistent point in time. Each check reads the database
+// using the Conn it is passed and returns an error if its invariant is violated. AssertConsistent returns the first
+// error returned, without running the remaining checks, or nil if all pass. The checks should only read: the transaction
+// is always rolled back, so any updates they make are discarded. Like any transaction callback, a check may be run
+// more than once if the snapshot restarts.
+func (conn *Conn) AssertConsistent(checks ...func(*Conn) error) error {
+	err := conn.Snapshot(func(conn *Conn) error {
+		for _, check := range checks {
+			if err := check(conn); err != nil {
+				return err
+			}
+		}
+		return errChecksPassed
+	})
+	if err == errChecksPassed {
+		return nil
+	}
+	return err
+}
+
+// SetSkipReadOnlyTP sets whether Transaction() first runs its callback outside TP in case it makes no database updates.
+// Read-only callbacks then complete without the overhead of starting and committing a transaction, but their reads
+// are not isolated from concurrent updates; use Snapshot() when reads must be consistent.
+// The first attempt by the callback to update the database (Set, Delete, DeleteTree, Increment, Lock or Unlock) returns
+// an internal error without making the update, and Transaction() then runs the callback again within TP whatever the
+// callback returned. As with transaction restarts, the callback must not have side effects other than on the database.
+// This setting is off by default and only affects transactions that are not nested within another transaction.
+func (conn *Conn) SetSkipReadOnlyTP(skip bool) {
+	conn.skipTP = skip
+}
+
+// probeWrite records that a database write was attempted while Transaction() was probing for a read-only callback.
+func (conn *Conn) probeWrite() error {
+	conn.wrote = true
+	return errProbeWrite
+}
@@ -0,0 +1,942 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Test that transactions commit or roll back as appropriate.
+func TestTransaction(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^tptest", "value")
+	t.Run("Commit", func(t *testing.T) {
+		err := conn.Transaction(func(conn *Conn) error {
+			return n.Set("committed")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if val, _ := n.Get(); val != "committed" {
+			t.Errorf("got %s, want %s", val, "committed")
+		}
+	})
+	t.Run("Rollback", func(t *testing.T) {
+		myErr := errors.New("callback failed")
+		err := conn.Transaction(func(conn *Conn) error {
+			n.Set("rolled back")
+			return myErr
+		})
+		if err != myErr {
+			t.Errorf("got error %v, want %v", err, myErr)
+		}
+		if val, _ := n.Get(); val != "committed" {
+			t.Errorf("got %s, want %s", val, "committed")
+		}
+		err = conn.Transaction(func(conn *Conn) error {
+			n.Set("rolled back")
+			return ErrRollback
+		})
+		if !errors.Is(err, ErrRollback) {
+			t.Errorf("got error %v, want %v", err, ErrRollback)
+		}
+		if val, _ := n.Get(); val != "committed" {
+			t.Errorf("got %s, want %s", val, "committed")
+		}
+	})
+	t.Run("Restart", func(t *testing.T) {
+		calls := 0
+		err := conn.Transaction(func(conn *Conn) error {
+			calls++
+			if calls < 3 {
+				return ErrRestart
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if calls != 3 {
+			t.Errorf("got %d calls, want %d", calls, 3)
+		}
+	})
+}
+
+// Test that Snapshot never sees a torn read while another goroutine updates the nodes it reads.
+func TestSnapshot(t *testing.T) {
+	const updates = 200
+	writer := NewConn()
+	a, b := writer.Node("^snapshot", "a"), writer.Node("^snapshot", "b")
+	a.Set("0")
+	b.Set("0")
+
+	// The writer always updates both nodes to the same value within one transaction.
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= updates; i++ {
+			err := writer.Transaction(func(conn *Conn) error {
+				val := strconv.Itoa(i)
+				if err := a.Set(val); err != nil {
+					return err
+				}
+				return b.Set(val)
+			})
+			if err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	reader := NewConn()
+	ra, rb := reader.Node("^snapshot", "a"), reader.Node("^snapshot", "b")
+	for range updates {
+		var valA, valB string
+		err := reader.Snapshot(func(conn *Conn) error {
+			var err error
+			valA, err = ra.Get()
+			if err != nil {
+				return err
+			}
+			valB, err = rb.Get()
+			return err
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if valA != valB {
+			t.Fatalf("torn read: got a=%s, b=%s", valA, valB)
+		}
+	}
+	wg.Wait()
+}
+
+// Test that nesting transactions beyond MaxTPDepth returns ErrTPTooDeep rather than an engine error.
+func TestTransactionDepth(t *testing.T) {
+	conn := NewConn()
+	var maxLevel string
+	var nest func(depth int) error
+	nest = func(depth int) error {
+		return conn.Transaction(func(conn *Conn) error {
+			if depth < MaxTPDepth {
+				return nest(depth + 1)
+			}
+			var err error
+			maxLevel, err = conn.Node("$TLEVEL").Get()
+			if err != nil {
+				return err
+			}
+			return nest(depth + 1) // one level more than YottaDB allows
+		})
+	}
+	err := nest(1)
+	if !errors.Is(err, ErrTPTooDeep) {
+		t.Errorf("got error %v, want %v", err, ErrTPTooDeep)
+	}
+	if maxLevel != strconv.Itoa(MaxTPDepth) {
+		t.Errorf("got $TLEVEL %s, want %d", maxLevel, MaxTPDepth)
+	}
+	// The guard must be released after the transactions unwind.
+	if err := conn.Transaction(func(*Conn) error { return nil }); err != nil {
+		t.Error(err)
+	}
+}
+
+// Test that SetSkipReadOnlyTP skips TP for read-only callbacks but still commits callbacks that update the database.
+func TestSkipReadOnlyTP(t *testing.T) {
+	conn := NewConn()
+	conn.SetSkipReadOnlyTP(true)
+	n := conn.Node("^skiptp")
+	n.Set("0")
+	for _, test := range []struct {
+		name   string
+		update func() error
+		calls  int
+		tlevel string // $TLEVEL during the last call
+	}{
+		{"ReadOnly", func() error { return nil }, 1, "0"},
+		{"Set", func() error { return n.Set("1") }, 2, "1"},
+		{"Delete", func() error { return n.Child("x").Delete() }, 2, "1"},
+		{"Increment", func() error { _, err := n.Increment(""); return err }, 2, "1"},
+		{"Lock", func() error { return n.Lock(0) }, 2, "1"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			calls := 0
+			var tlevel string
+			err := conn.Transaction(func(conn *Conn) error {
+				calls++
+				tlevel = conn.Node("$TLEVEL").MustGet()
+				n.MustGet()
+				test.update() // errors deliberately ignored: a write must still force TP
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if calls != test.calls || tlevel != test.tlevel {
+				t.Errorf("got %d calls with $TLEVEL %s, want %d calls with $TLEVEL %s", calls, tlevel, test.calls, test.tlevel)
+			}
+		})
+	}
+	n.Unlock()
+	if val := n.MustGet(); val != "2" {
+		t.Errorf("got %s, want %s", val, "2")
+	}
+}
+
+// Benchmark read-only transactions with and without SetSkipReadOnlyTP.
+func BenchmarkReadOnlyTransaction(b *testing.B) {
+	for _, skip := range []bool{false, true} {
+		b.Run(fmt.Sprintf("Skip=%v", skip), func(b *testing.B) {
+			conn := NewConn()
+			conn.SetSkipReadOnlyTP(skip)
+			n := conn.Node("^readonly")
+			n.Set("value")
+			for b.Loop() {
+				conn.Transaction(func(conn *Conn) error {
+					_, err := n.Get()
+					return err
+				})
+			}
+		})
+	}
+}
+
+// Test that a failing validate callback rolls back a transaction even though fn succeeds.
+func TestTransactionValidate(t *testing.T) {
+	conn := NewConn()
+	balance := conn.Node("^validate", "balance")
+	balance.Set("10")
+	nonNegative := func() error {
+		val, err := balance.Get()
+		if err != nil {
+			return err
+		}
+		if n, _ := strconv.Atoi(val); n < 0 {
+			return errors.New("balance is negative")
+		}
+		return nil
+	}
+	withdraw := func(amount string) func(*Conn) error {
+		return func(conn *Conn) error {
+			_, err := balance.Increment("-" + amount)
+			return err
+		}
+	}
+
+	if err := conn.Transaction(withdraw("4"), nonNegative); err != nil {
+		t.Fatal(err)
+	}
+	err := conn.Transaction(withdraw("7"), nonNegative)
+	if err == nil || err.Error() != "balance is negative" {
+		t.Errorf("got error %v, want %s", err, "balance is negative")
+	}
+	if val := balance.MustGet(); val != "6" {
+		t.Errorf("got %s, want %s", val, "6")
+	}
+}
+
+// Test that TxStats reports the reason for each restart.
+func TestTxStats(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^txstats")
+	for _, test := range []struct {
+		name    string
+		restart error
+		want    RestartReason
+	}{
+		{"manual", ErrRestart, RestartManual},
+		// a database operation reports a conflict with a YDB_TP_RESTART error of its own
+		{"passed on", Error(YDB_TP_RESTART, "YDB: conflict"), RestartConflict},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			attempts := 0
+			err := conn.Transaction(func(conn *Conn) error {
+				attempts++
+				if attempts == 1 {
+					return test.restart
+				}
+				return n.Set("done")
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			stats := conn.TxStats()
+			if stats.Attempts != 2 || !slices.Equal(stats.Restarts, []RestartReason{test.want}) {
+				t.Errorf("got %+v, want 2 attempts restarted by %v", stats, test.want)
+			}
+		})
+	}
+	// Another process updates a node the transaction read, so YottaDB restarts it when it tries to commit
+	t.Run("conflict", func(t *testing.T) {
+		attempts := 0
+		err := conn.Transaction(func(conn *Conn) error {
+			attempts++
+			if _, err := n.Get(); err != nil && !isUndef(err) {
+				return err
+			}
+			if attempts == 1 {
+				if err := setElsewhere("other", "^txstats"); err != nil {
+					return err
+				}
+			}
+			return n.Set("done")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		stats := conn.TxStats()
+		if stats.Attempts != 2 || !slices.Equal(stats.Restarts, []RestartReason{RestartConflict}) {
+			t.Errorf("got %+v, want 2 attempts restarted by %v", stats, RestartConflict)
+		}
+	})
+	if err := conn.Transaction(func(conn *Conn) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	if stats := conn.TxStats(); stats.Attempts != 1 || len(stats.Restarts) != 0 {
+		t.Errorf("got %+v, want 1 attempt and no restarts", stats)
+	}
+}
+
+// setElsewhere makes a separate process set the node given by varname and subscripts to val, so that a transaction in
+// this process that used the node conflicts with it. It returns once the node is set. Unlike holdLock() it does not
+// take t, so that it may be called within a transaction callback.
+func setElsewhere(val, varname string, subscripts ...string) error {
+	cmd := exec.Command(os.Args[0], "-test.run=^TestHelperSet$")
+	cmd.Env = append(os.Environ(), "YDBGO_SET="+strings.Join(append([]string{val, varname}, subscripts...), ","))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("setting process failed: %w\n%s", err, out)
+	}
+	return nil
+}
+
+// TestHelperSet is not a real test: it is run as a subprocess by setElsewhere() to set a node.
+func TestHelperSet(t *testing.T) {
+	spec := os.Getenv("YDBGO_SET")
+	if spec == "" {
+		t.Skip("only run as a subprocess by setElsewhere()")
+	}
+	names := strings.Split(spec, ",")
+	if err := NewConn().Node(names[1], names[2:]...).Set(names[0]); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Test that ProcessInfo reports the process id and the transaction level inside and outside transactions.
+func TestProcessInfo(t *testing.T) {
+	conn := NewConn()
+	info, err := conn.ProcessInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.PID != os.Getpid() || info.TLevel != 0 || info.TRestart != 0 || info.Release == "" {
+		t.Errorf("got %+v outside a transaction, want pid %d and level 0", info, os.Getpid())
+	}
+	attempts := 0
+	err = conn.Transaction(func(conn *Conn) error {
+		attempts++
+		if attempts == 1 {
+			return ErrRestart
+		}
+		if info, err = conn.ProcessInfo(); err != nil {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.TLevel != 1 || info.TRestart != 1 {
+		t.Errorf("got %+v inside a restarted transaction, want level 1 and 1 restart", info)
+	}
+	err = conn.Transaction(func(conn *Conn) error {
+		return conn.Transaction(func(conn *Conn) error {
+			info, err = conn.ProcessInfo()
+			return err
+		})
+	})
+	if err != nil || info.TLevel != 2 {
+		t.Errorf("got %+v, error %v in a nested transaction, want level 2", info, err)
+	}
+}
+
+// Test that OnceByKey runs fn just once for a key however many times the key is submitted concurrently.
+func TestOnceByKey(t *testing.T) {
+	NewConn().Node("^oncebykey").DeleteTree()
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn := NewConn()
+			key := conn.Node("^oncebykey", "request", "abc")
+			for range 5 {
+				err := conn.OnceByKey(key, func() error {
+					_, err := conn.Node("^oncebykey", "runs").Increment("")
+					return err
+				})
+				if err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	conn := NewConn()
+	if runs := conn.Node("^oncebykey", "runs").MustGet(); runs != "1" {
+		t.Errorf("got %s runs, want 1", runs)
+	}
+	if _, err := conn.Node("^oncebykey", "request", "abc").GetTime(); err != nil {
+		t.Errorf("got error %v reading marker time", err)
+	}
+
+	failure := errors.New("failure")
+	key := conn.Node("^oncebykey", "request", "failing")
+	if err := conn.OnceByKey(key, func() error { return failure }); err != failure {
+		t.Errorf("got error %v, want %v", err, failure)
+	}
+	if data, _ := key.Data(); data != 0 {
+		t.Errorf("got marker set after fn failed")
+	}
+}
+
+// Test that a panic in a transaction callback rolls back the transaction and leaves the connection usable.
+func TestTransactionPanic(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^txpanic")
+	n.DeleteTree()
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("got panic %v, want %v", r, "boom")
+			}
+		}()
+		conn.Transaction(func(conn *Conn) error {
+			n.Child("outer").Set("x")
+			return conn.Transaction(func(conn *Conn) error {
+				n.Child("inner").Set("y")
+				panic("boom")
+			})
+		})
+		t.Errorf("got no panic")
+	}()
+	if data, _ := n.Data(); data != 0 {
+		t.Errorf("got data %d after panic, want nothing committed", data)
+	}
+	if conn.tpDepth != 0 {
+		t.Errorf("got transaction depth %d after panic, want 0", conn.tpDepth)
+	}
+	if err := conn.Transaction(func(conn *Conn) error { return n.Set("after") }); err != nil {
+		t.Fatal(err)
+	}
+	if val := n.MustGet(); val != "after" {
+		t.Errorf("got %s, want %s", val, "after")
+	}
+}
+
+// Test that a savepoint that fails rolls back only its own updates.
+func TestSavepoint(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^savepoint")
+	n.DeleteTree()
+	failure := errors.New("optional step failed")
+	err := conn.Transaction(func(conn *Conn) error {
+		if err := n.Child("before").Set("x"); err != nil {
+			return err
+		}
+		err := conn.Savepoint(func() error {
+			n.Child("optional").Set("x")
+			return failure
+		})
+		if errors.Is(err, ErrRestart) {
+			return err
+		}
+		if err != failure {
+			t.Errorf("got savepoint error %v, want %v", err, failure)
+		}
+		if err := conn.Savepoint(func() error { return n.Child("kept").Set("x") }); err != nil {
+			return err
+		}
+		return n.Child("after").Set("x")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for sub, want := range map[string]int{"before": 1, "optional": 0, "kept": 1, "after": 1} {
+		if data, _ := n.Child(sub).Data(); data != want {
+			t.Errorf("%s: got data %d, want %d", sub, data, want)
+		}
+	}
+	if err := conn.Savepoint(func() error { return nil }); err != ErrNotInTransaction {
+		t.Errorf("got error %v, want %v", err, ErrNotInTransaction)
+	}
+}
+
+// Test that InTransaction and TPToken reflect whether operations are transactional.
+func TestTPToken(t *testing.T) {
+	conn := NewConn()
+	if conn.InTransaction() || conn.TPToken() != NOTTP {
+		t.Errorf("got InTransaction %v and token %d outside a transaction, want false and NOTTP", conn.InTransaction(), conn.TPToken())
+	}
+	var outer, inner uint64
+	err := conn.Transaction(func(conn *Conn) error {
+		if !conn.InTransaction() {
+			t.Errorf("got InTransaction false within a transaction")
+		}
+		outer = conn.TPToken()
+		return conn.Transaction(func(conn *Conn) error {
+			inner = conn.TPToken()
+			return nil
+		})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outer == NOTTP || inner == NOTTP {
+		t.Errorf("got tokens %d and %d within transactions, want transaction tokens", outer, inner)
+	}
+	if conn.InTransaction() || conn.TPToken() != NOTTP {
+		t.Errorf("got InTransaction %v and token %d after the transaction, want false and NOTTP", conn.InTransaction(), conn.TPToken())
+	}
+}
+
+// Test that deferred functions run once after commit and not after rollback.
+func TestDefer(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^defer")
+	var ran []string
+	attempts := 0
+	err := conn.Transaction(func(conn *Conn) error {
+		attempts++
+		conn.Defer(func() { ran = append(ran, fmt.Sprintf("outer%d", attempts)) })
+		conn.Savepoint(func() error {
+			conn.Defer(func() { ran = append(ran, "discarded") })
+			return errors.New("rolled back")
+		})
+		conn.Savepoint(func() error {
+			conn.Defer(func() { ran = append(ran, "inner") })
+			return nil
+		})
+		if len(ran) > 0 {
+			t.Errorf("got %v run before commit", ran)
+		}
+		if attempts == 1 {
+			return ErrRestart
+		}
+		return n.Set("x")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"outer2", "inner"}; !slices.Equal(ran, want) {
+		t.Errorf("got %v run, want %v", ran, want)
+	}
+
+	ran = nil
+	err = conn.Transaction(func(conn *Conn) error {
+		conn.Defer(func() { ran = append(ran, "rollback") })
+		return ErrRollback
+	})
+	if err != ErrRollback || len(ran) != 0 {
+		t.Errorf("got %v run and error %v after rollback, want nothing run", ran, err)
+	}
+
+	conn.SetSkipReadOnlyTP(true)
+	defer conn.SetSkipReadOnlyTP(false)
+	for _, write := range []bool{false, true} {
+		ran = nil
+		conn.Transaction(func(conn *Conn) error {
+			conn.Defer(func() { ran = append(ran, "probe") })
+			if write {
+				return n.Set("y")
+			}
+			return nil
+		})
+		if len(ran) != 1 {
+			t.Errorf("write %v: got %v run, want it run once", write, ran)
+		}
+	}
+}
+
+// Test that OnCommit reports the nodes updated by each committed transaction.
+func TestOnCommit(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^oncommit")
+	n.DeleteTree()
+	var changed []string
+	conn.OnCommit(func(nodes []*Node) {
+		changed = nil
+		for _, node := range nodes {
+			changed = append(changed, node.String())
+		}
+	})
+	defer conn.OnCommit(nil)
+	attempts := 0
+	err := conn.Transaction(func(conn *Conn) error {
+		attempts++
+		if attempts == 1 {
+			n.Child("restarted").Set("x")
+			return ErrRestart
+		}
+		n.Child("a").Set("1")
+		n.Child("b").Increment("")
+		n.Child("a").Set("2")
+		conn.Savepoint(func() error {
+			n.Child("rolledback").Set("x")
+			return ErrRollback
+		})
+		conn.Savepoint(func() error { return n.Child("nested").Set("x") })
+		return n.Child("c").Delete()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{`^oncommit("a")`, `^oncommit("b")`, `^oncommit("nested")`, `^oncommit("c")`}
+	if !slices.Equal(changed, want) {
+		t.Errorf("got changed %v, want %v", changed, want)
+	}
+
+	changed = nil
+	conn.Transaction(func(conn *Conn) error {
+		n.Child("x").Set("1")
+		return ErrRollback
+	})
+	n.Child("outside").Set("1")
+	if changed != nil {
+		t.Errorf("got changed %v without a commit, want no report", changed)
+	}
+}
+
+// Test that a transaction reads its own writes before it commits, with SetVerifyReads checking every read.
+func TestReadYourWrites(t *testing.T) {
+	conn := NewConn()
+	conn.SetVerifyReads(true)
+	n := conn.Node("^readwrites")
+	n.DeleteTree()
+	n.Set("before")
+	err := conn.Transaction(func(conn *Conn) error {
+		if err := n.Set("during"); err != nil {
+			return err
+		}
+		if val, err := n.Get(); err != nil || val != "during" {
+			t.Errorf("got %q, %v before commit, want %q", val, err, "during")
+		}
+		n.Child("count").Increment("5")
+		conn.Savepoint(func() error {
+			n.Child("count").Increment("")
+			n.Child("count").Get()
+			return ErrRollback
+		})
+		if val, err := n.Child("count").Get(); err != nil || val != "5" {
+			t.Errorf("got %q, %v after rolled back savepoint, want %q", val, err, "5")
+		}
+		n.DeleteTree()
+		if val, err := n.Get("gone"); err != nil || val != "gone" {
+			t.Errorf("got %q, %v after delete, want %q", val, err, "gone")
+		}
+		return n.Set("after")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val := n.MustGet(); val != "after" {
+		t.Errorf("got %q after commit, want %q", val, "after")
+	}
+
+	// The check detects a read that does not match the transaction's write, by any function that reads the value
+	reads := map[string]func(){
+		"ReadChunks":  func() { n.ReadChunks(10, func([]byte) error { return nil }) },
+		"ValueLen":    func() { n.ValueLen() },
+		"GetCapped":   func() { n.GetCapped(1) },
+		"ValueEquals": func() { n.ValueEquals([]byte("x")) },
+	}
+	for name, read := range reads {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !errors.Is(r.(error), ErrReadYourWrites) {
+					t.Errorf("got panic %v from %s, want ErrReadYourWrites", r, name)
+				}
+			}()
+			conn.Transaction(func(conn *Conn) error {
+				n.Set("x")
+				conn.tx.written[n.Key()] = "y" // simulate a write that was not seen
+				read()
+				return nil
+			})
+		}()
+	}
+	defer func() {
+		if r := recover(); r == nil || !errors.Is(r.(error), ErrReadYourWrites) {
+			t.Errorf("got panic %v, want ErrReadYourWrites", r)
+		}
+	}()
+	conn.Transaction(func(conn *Conn) error {
+		n.Set("x")
+		conn.tx.written[n.Key()] = "y" // simulate a write that was not seen
+		n.Get()
+		return nil
+	})
+}
+
+// Test that TransactionWithRetry gives up on a transaction that keeps restarting once its budget elapses.
+func TestTransactionWithRetry(t *testing.T) {
+	conn := NewConn()
+	isv := conn.Node("$ZMAXTPTIME")
+	before := isv.MustGet()
+	budget := 50 * time.Millisecond
+	attempts := 0
+	start := time.Now()
+	err := conn.TransactionWithRetry(2*time.Second, budget, func(conn *Conn) error {
+		attempts++
+		if got := isv.MustGet(); got != "2" {
+			t.Errorf("got $ZMAXTPTIME %s during the transaction, want 2", got)
+		}
+		time.Sleep(time.Millisecond)
+		return ErrRestart
+	})
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("got error %v, want ErrBudgetExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed < budget || elapsed > 20*budget {
+		t.Errorf("got return after %v, want about %v", elapsed, budget)
+	}
+	if attempts < 2 {
+		t.Errorf("got %d attempts, want several", attempts)
+	}
+	if got := isv.MustGet(); got != before {
+		t.Errorf("got $ZMAXTPTIME %s after the transaction, want it restored to %s", got, before)
+	}
+
+	n := conn.Node("^retry")
+	err = conn.TransactionWithRetry(0, budget, func(conn *Conn) error { return n.Set("done") })
+	if err != nil || n.MustGet() != "done" {
+		t.Errorf("got error %v, value %q, want the transaction to commit", err, n.MustGet())
+	}
+
+	// Setting $ZMAXTPTIME is not an application update, so it is not recorded
+	var recording strings.Builder
+	conn.StartRecording(&recording)
+	conn.TransactionWithRetry(time.Second, 0, func(conn *Conn) error { return n.Set("recorded") })
+	if err := conn.StopRecording(); err != nil || strings.Contains(recording.String(), "ZMAXTPTIME") {
+		t.Errorf("got recording %q, error %v; want only the update of %v", recording.String(), err, n)
+	}
+
+	// A read-only probe run first is not an attempt that counts against the budget
+	conn.SetSkipReadOnlyTP(true)
+	defer conn.SetSkipReadOnlyTP(false)
+	err = conn.TransactionWithRetry(0, time.Nanosecond, func(conn *Conn) error {
+		time.Sleep(time.Millisecond)
+		return n.Set("after probe")
+	})
+	if err != nil || n.MustGet() != "after probe" {
+		t.Errorf("got error %v, value %q, want the first attempt within TP to commit", err, n.MustGet())
+	}
+}
+
+// Test that BatchedTransaction applies every item, in transactions of the requested size.
+func TestBatchedTransaction(t *testing.T) {
+	conn := NewConn()
+	n := conn.Node("^batched")
+	n.DeleteTree()
+	items := make([]WorkItem, 1000)
+	for i := range items {
+		items[i] = i
+	}
+	var sizes []int
+	conn.OnCommit(func(changed []*Node) { sizes = append(sizes, len(changed)) })
+	defer conn.OnCommit(nil)
+	err := conn.BatchedTransaction(items, 100, func(conn *Conn, item WorkItem) error {
+		return n.Child(strconv.Itoa(item.(int))).Set("done")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := slices.Repeat([]int{100}, 10); !slices.Equal(sizes, want) {
+		t.Errorf("got transactions of sizes %v, want %v", sizes, want)
+	}
+	count := 0
+	for child := range n.Children() {
+		if child.MustGet() == "done" {
+			count++
+		}
+	}
+	if count != len(items) {
+		t.Errorf("got %d items applied, want %d", count, len(items))
+	}
+
+	// A failure rolls back only its own transaction
+	n.DeleteTree()
+	err = conn.BatchedTransaction(items[:250], 100, func(conn *Conn, item WorkItem) error {
+		if item.(int) == 150 {
+			return ErrRollback
+		}
+		return n.Child(strconv.Itoa(item.(int))).Set("done")
+	})
+	if !errors.Is(err, ErrRollback) {
+		t.Errorf("got error %v, want ErrRollback", err)
+	}
+	if got := slices.Collect(n.ChildSubscripts()); len(got) != 100 {
+		t.Errorf("got %d items applied, want the first 100", len(got))
+	}
+}
+
+// Test that locks taken with TxLock are released on restart, so that they are consistent on retry, and when the
+// transaction ends.
+func TestTxLock(t *testing.T) {
+	conn := NewConn()
+	a, b := conn.Node("^txlock", "a"), conn.Node("^txlock", "b")
+	if err := conn.TxLock(0, a); err != ErrNotInTransaction {
+		t.Errorf("got error %v outside a transaction, want ErrNotInTransaction", err)
+	}
+	var counts [][2]int
+	err := conn.Transaction(func(conn *Conn) error {
+		if err := conn.TxLock(time.Second, a, b); err != nil {
+			return err
+		}
+		conn.Savepoint(func() error {
+			conn.TxLock(0, b)
+			return ErrRollback
+		})
+		counts = append(counts, [2]int{addLockCount(a, 0), addLockCount(b, 0)})
+		if len(counts) == 1 {
+			return ErrRestart
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := [][2]int{{1, 1}, {1, 1}}; !slices.Equal(counts, want) {
+		t.Errorf("got lock counts %v on each attempt, want %v", counts, want)
+	}
+	if ca, cb := addLockCount(a, 0), addLockCount(b, 0); ca != 0 || cb != 0 {
+		t.Errorf("got lock counts %d, %d after commit, want 0", ca, cb)
+	}
+
+	conn.Transaction(func(conn *Conn) error {
+		conn.TxLock(0, a)
+		return ErrRollback
+	})
+	if count := addLockCount(a, 0); count != 0 {
+		t.Errorf("got lock count %d after rollback, want 0", count)
+	}
+}
+
+// Test that the OnRestart handler is called before each retry of a restarting transaction.
+func TestOnRestart(t *testing.T) {
+	conn := NewConn()
+	var attempts []int
+	var reasons []string
+	conn.OnRestart(func(attempt int, reason string) {
+		attempts = append(attempts, attempt)
+		reasons = append(reasons, reason)
+	})
+	defer conn.OnRestart(nil)
+	calls := 0
+	err := conn.TransactionWithRetry(0, time.Minute, func(conn *Conn) error {
+		calls++
+		if calls <= 3 {
+			return ErrRestart
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []int{1, 2, 3}; !slices.Equal(attempts, want) {
+		t.Errorf("got attempts %v, want %v", attempts, want)
+	}
+	for _, reason := range reasons {
+		if reason != RestartManual.String() {
+			t.Errorf("got reason %q, want %q", reason, RestartManual.String())
+		}
+	}
+}
+
+// Test that AssertConsistent returns the first failing check, and that checks see a consistent snapshot while
+// another goroutine moves value between the nodes they read.
+func TestAssertConsistent(t *testing.T) {
+	conn := NewConn()
+	a, b := conn.Node("^consistent", "a"), conn.Node("^consistent", "b")
+	a.Set("50")
+	b.Set("50")
+	sumIs100 := func(conn *Conn) error {
+		va, _ := strconv.Atoi(a.on(conn).MustGet())
+		vb, _ := strconv.Atoi(b.on(conn).MustGet())
+		if va+vb != 100 {
+			return fmt.Errorf("got a+b=%d, want 100", va+vb)
+		}
+		return nil
+	}
+	errNegative := errors.New("negative")
+	nonNegative := func(conn *Conn) error {
+		for _, n := range []*Node{a, b} {
+			if v, _ := strconv.Atoi(n.on(conn).MustGet()); v < 0 {
+				return errNegative
+			}
+		}
+		return nil
+	}
+
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		conn := NewConn()
+		a, b := a.on(conn), b.on(conn)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			conn.Transaction(func(conn *Conn) error {
+				a.Increment("-1")
+				b.Increment("1")
+				return nil
+			})
+		}
+	}()
+	for range 200 {
+		if err := conn.AssertConsistent(sumIs100); err != nil {
+			t.Error(err)
+			break
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	a.Set("-5")
+	b.Set("105")
+	ran := false
+	err := conn.AssertConsistent(sumIs100, nonNegative, func(conn *Conn) error { ran = true; return nil })
+	if err != errNegative || ran {
+		t.Errorf("got error %v with later check run %v, want %v and not run", err, ran, errNegative)
+	}
+	err = conn.AssertConsistent(func(conn *Conn) error { return a.on(conn).Set("999") })
+	if err != nil || a.MustGet() != "-5" {
+		t.Errorf("got error %v and a=%s, want no error and the update discarded", err, a.MustGet())
+	}
+}
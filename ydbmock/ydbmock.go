@@ -0,0 +1,257 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Package ydbmock provides an in-memory implementation of yottadb.NodeAPI for unit testing code that uses YottaDB
+// without a database. It imitates YottaDB's behaviour for the operations in NodeAPI, including the collation order of
+// subscripts and the errors returned for nodes without a value, but has no transactions, locks or persistence.
+package ydbmock
+
+import (
+	"iter"
+	"maps"
+	"math/big"
+	"slices"
+	"strings"
+	"sync"
+
+	"lang.yottadb.com/go/yottadb/v2"
+)
+
+// DB is an in-memory database of local and global variables. It is safe for concurrent use.
+type DB struct {
+	mu   sync.Mutex
+	vars map[string]*entry
+}
+
+// entry holds the value and children of one node of a DB.
+type entry struct {
+	value    string
+	hasValue bool
+	children map[string]*entry
+}
+
+// Node represents a node of a DB and implements yottadb.NodeAPI.
+type Node struct {
+	db         *DB
+	varname    string
+	subscripts []string
+}
+
+var _ yottadb.NodeAPI = (*Node)(nil)
+
+// New returns a new empty DB.
+func New() *DB {
+	return &DB{vars: make(map[string]*entry)}
+}
+
+// Node returns a Node of db with the given varname and subscripts, like yottadb.Conn.Node().
+func (db *DB) Node(varname string, subscripts ...string) *Node {
+	return &Node{db: db, varname: varname, subscripts: slices.Clone(subscripts)}
+}
+
+// Child returns a new Node with the given subscripts appended to the subscripts of n, like yottadb.Node.Child().
+func (n *Node) Child(subscripts ...string) *Node {
+	return n.db.Node(n.varname, append(slices.Clone(n.subscripts), subscripts...)...)
+}
+
+// String returns n in the same format as yottadb.Node.String(): `varname("sub1")("sub2")`, with double quotes within
+// subscripts doubled.
+func (n *Node) String() string {
+	var bld strings.Builder
+	bld.WriteString(n.varname)
+	for _, sub := range n.subscripts {
+		bld.WriteString(`("` + strings.ReplaceAll(sub, `"`, `""`) + `")`)
+	}
+	return bld.String()
+}
+
+// find returns the entries on the path from n's variable down to n itself, creating any that are missing if create
+// is true. If create is false and n does not exist, the path is truncated at the last entry that exists.
+// The caller must hold n.db.mu.
+func (n *Node) find(create bool) []*entry {
+	e := n.db.vars[n.varname]
+	if e == nil {
+		if !create {
+			return nil
+		}
+		e = &entry{children: make(map[string]*entry)}
+		n.db.vars[n.varname] = e
+	}
+	path := []*entry{e}
+	for _, sub := range n.subscripts {
+		child := e.children[sub]
+		if child == nil {
+			if !create {
+				return path
+			}
+			child = &entry{children: make(map[string]*entry)}
+			e.children[sub] = child
+		}
+		e = child
+		path = append(path, e)
+	}
+	return path
+}
+
+// lookup returns the entry for n, or nil if it does not exist. The caller must hold n.db.mu.
+func (n *Node) lookup() *entry {
+	path := n.find(false)
+	if len(path) != len(n.subscripts)+1 {
+		return nil
+	}
+	return path[len(path)-1]
+}
+
+// prune removes entries on the path to n that no longer have a value or children. The caller must hold n.db.mu.
+func (n *Node) prune() {
+	path := n.find(false)
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].hasValue || len(path[i].children) > 0 {
+			return
+		}
+		if i == 0 {
+			delete(n.db.vars, n.varname)
+		} else {
+			delete(path[i-1].children, n.subscripts[i-1])
+		}
+	}
+}
+
+// undef returns the error YottaDB returns for reading n when it has no value.
+func (n *Node) undef() error {
+	if strings.HasPrefix(n.varname, "^") {
+		return yottadb.Error(yottadb.YDB_ERR_GVUNDEF, "%YDB-E-GVUNDEF, Global variable undefined: "+n.String())
+	}
+	return yottadb.Error(yottadb.YDB_ERR_LVUNDEF, "%YDB-E-LVUNDEF, Undefined local variable: "+n.String())
+}
+
+// Get returns the value of n. If n has no value it returns deflt[0] if supplied, or else the same error as YottaDB.
+func (n *Node) Get(deflt ...string) (string, error) {
+	n.db.mu.Lock()
+	defer n.db.mu.Unlock()
+	if e := n.lookup(); e != nil && e.hasValue {
+		return e.value, nil
+	}
+	if len(deflt) > 0 {
+		return deflt[0], nil
+	}
+	return "", n.undef()
+}
+
+// Set sets the value of n.
+func (n *Node) Set(val string) error {
+	n.db.mu.Lock()
+	defer n.db.mu.Unlock()
+	path := n.find(true)
+	e := path[len(path)-1]
+	e.value, e.hasValue = val, true
+	return nil
+}
+
+// Delete removes the value of n, leaving any subtree below it intact.
+func (n *Node) Delete() error {
+	n.db.mu.Lock()
+	defer n.db.mu.Unlock()
+	if e := n.lookup(); e != nil {
+		e.value, e.hasValue = "", false
+		n.prune()
+	}
+	return nil
+}
+
+// DeleteTree removes the value of n and its entire subtree.
+func (n *Node) DeleteTree() error {
+	n.db.mu.Lock()
+	defer n.db.mu.Unlock()
+	if e := n.lookup(); e != nil {
+		e.value, e.hasValue = "", false
+		clear(e.children)
+		n.prune()
+	}
+	return nil
+}
+
+// Increment adds amount to the numeric value of n and returns the result, like yottadb.Node.Increment().
+// If amount is "" it increments by 1. Unlike YottaDB, a value that is not a number is treated as 0 rather than
+// as the number that it starts with, and results are not limited to YottaDB's numeric precision.
+func (n *Node) Increment(amount string) (string, error) {
+	if amount == "" {
+		amount = "1"
+	}
+	by, ok := new(big.Rat).SetString(amount)
+	if !ok {
+		by = new(big.Rat)
+	}
+	n.db.mu.Lock()
+	defer n.db.mu.Unlock()
+	path := n.find(true)
+	e := path[len(path)-1]
+	result, ok := new(big.Rat).SetString(e.value)
+	if !e.hasValue || !ok {
+		result = new(big.Rat)
+	}
+	result.Add(result, by)
+	e.value, e.hasValue = canonical(result), true
+	return e.value, nil
+}
+
+// canonical returns the finite decimal r in YottaDB's canonical number format, e.g. "12", "-1.5" or ".25".
+func canonical(r *big.Rat) string {
+	s := strings.TrimRight(strings.TrimRight(r.FloatString(64), "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	if rest, ok := strings.CutPrefix(s, "-0."); ok {
+		return "-." + rest
+	}
+	if rest, ok := strings.CutPrefix(s, "0."); ok {
+		return "." + rest
+	}
+	return s
+}
+
+// Data returns whether n has a value and/or a subtree as 0, 1, 10 or 11, like yottadb.Node.Data().
+func (n *Node) Data() (int, error) {
+	n.db.mu.Lock()
+	defer n.db.mu.Unlock()
+	e := n.lookup()
+	if e == nil {
+		return 0, nil
+	}
+	data := 0
+	if e.hasValue {
+		data += 1
+	}
+	if len(e.children) > 0 {
+		data += 10
+	}
+	return data, nil
+}
+
+// ChildSubscripts returns an iterator over the subscripts of the immediate children of n in YottaDB collation order.
+// It iterates over the children that existed when iteration started.
+func (n *Node) ChildSubscripts() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		n.db.mu.Lock()
+		var subs []string
+		if e := n.lookup(); e != nil {
+			subs = slices.Collect(maps.Keys(e.children))
+		}
+		n.db.mu.Unlock()
+		slices.SortFunc(subs, yottadb.Collate)
+		for _, sub := range subs {
+			if !yield(sub) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package ydbmock
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"lang.yottadb.com/go/yottadb/v2"
+)
+
+// countVisit is an example of application code written against yottadb.NodeAPI so that it can be tested with a mock.
+func countVisit(page yottadb.NodeAPI) (string, error) {
+	return page.Increment("")
+}
+
+// Test the mock implementation of NodeAPI.
+func TestMock(t *testing.T) {
+	db := New()
+	n := db.Node("^mock")
+
+	t.Run("Get", func(t *testing.T) {
+		_, err := n.Get()
+		var ydbErr *yottadb.YDBError
+		if !errors.As(err, &ydbErr) || ydbErr.Code() != yottadb.YDB_ERR_GVUNDEF {
+			t.Errorf("got error %v, want YDB_ERR_GVUNDEF", err)
+		}
+		if _, err := db.Node("local").Get(); !errors.As(err, &ydbErr) || ydbErr.Code() != yottadb.YDB_ERR_LVUNDEF {
+			t.Errorf("got error %v, want YDB_ERR_LVUNDEF", err)
+		}
+		if val, _ := n.Get("default"); val != "default" {
+			t.Errorf("got %s, want %s", val, "default")
+		}
+		n.Set("value")
+		if val, _ := n.Get(); val != "value" {
+			t.Errorf("got %s, want %s", val, "value")
+		}
+	})
+
+	t.Run("Data", func(t *testing.T) {
+		n.Child("a", "b").Set("x")
+		for node, want := range map[*Node]int{n: 11, n.Child("a"): 10, n.Child("a", "b"): 1, n.Child("z"): 0} {
+			if got, _ := node.Data(); got != want {
+				t.Errorf("%v: got %d, want %d", node, got, want)
+			}
+		}
+		n.Child("a", "b").Delete()
+		if got, _ := n.Data(); got != 1 {
+			t.Errorf("got %d, want %d after deleting the only descendant", got, 1)
+		}
+		n.Child("a").Set("x")
+		n.DeleteTree()
+		if got, _ := n.Data(); got != 0 {
+			t.Errorf("got %d, want %d after DeleteTree", got, 0)
+		}
+	})
+
+	t.Run("Increment", func(t *testing.T) {
+		page := db.Node("^visits", "home")
+		for _, want := range []string{"1", "2"} {
+			if got, _ := countVisit(page); got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		}
+		for _, step := range []struct{ amount, want string }{{"-2.5", "-.5"}, {".75", ".25"}, {"-.25", "0"}} {
+			if got, _ := page.Increment(step.amount); got != step.want {
+				t.Errorf("incrementing by %s: got %s, want %s", step.amount, got, step.want)
+			}
+		}
+	})
+
+	t.Run("ChildSubscripts", func(t *testing.T) {
+		for _, sub := range []string{"b", "10", "a", "-1", "2"} {
+			n.Child(sub).Set("x")
+		}
+		got := slices.Collect(n.ChildSubscripts())
+		want := []string{"-1", "2", "10", "a", "b"}
+		if !slices.Equal(got, want) {
+			t.Errorf("got %v, want %v", got, want)
+		}
+		if s := n.Child("a", "b").String(); s != `^mock("a")("b")` {
+			t.Errorf("got %s, want %s", s, `^mock("a")("b")`)
+		}
+		if s := n.Child(`say "hi"`).String(); s != `^mock("say ""hi""")` {
+			t.Errorf("got %s, want %s", s, `^mock("say ""hi""")`)
+		}
+	})
+}
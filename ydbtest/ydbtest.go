@@ -0,0 +1,112 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Package ydbtest provides helpers for tests, such as in CI, that check that code using YottaDB behaves correctly when
+// run concurrently against a real database. Unlike package ydbmock, it requires a YottaDB database.
+package ydbtest
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+
+	"lang.yottadb.com/go/yottadb/v2"
+)
+
+// IncrementFunc adds 1 to the value of counter using conn, which is the Conn that counter belongs to.
+type IncrementFunc func(conn *yottadb.Conn, counter *yottadb.Node) error
+
+// ConcurrencyOptions specifies the test run by RunConcurrencyTest(). Zero fields take the defaults given.
+type ConcurrencyOptions struct {
+	Counter    string        // global variable to use as the counter; default "^ydbtestCounter"
+	Goroutines int           // number of goroutines incrementing the counter at once; default 8
+	Increments int           // number of increments made by each goroutine; default 100
+	Increment  IncrementFunc // how to increment the counter; default TransactionalIncrement
+}
+
+// TransactionalIncrement adds 1 to counter by reading and then setting it within a transaction, so that the
+// transaction, rather than Increment(), is what makes it atomic.
+func TransactionalIncrement(conn *yottadb.Conn, counter *yottadb.Node) error {
+	return conn.Transaction(func(conn *yottadb.Conn) error {
+		val, err := counter.Get("0")
+		if err != nil {
+			return err
+		}
+		count, err := strconv.Atoi(val)
+		if err != nil {
+			return err
+		}
+		return counter.Set(strconv.Itoa(count + 1))
+	})
+}
+
+// RunConcurrencyTest starts opts.Goroutines goroutines, each with its own Conn from newConn, that each increment a
+// shared counter opts.Increments times with opts.Increment, and then reports an error to t unless the counter equals
+// the total number of increments. Passing an IncrementFunc that wraps the application's own transactional logic checks
+// that it is atomic, since increments that are not lose updates when they interleave. The counter is deleted first.
+// RunConcurrencyTest reports errors from the goroutines and the final check with t.Errorf() once all goroutines have
+// finished, so it may be called with any testing.TB.
+func RunConcurrencyTest(t testing.TB, newConn func() *yottadb.Conn, opts ConcurrencyOptions) {
+	t.Helper()
+	if opts.Counter == "" {
+		opts.Counter = "^ydbtestCounter"
+	}
+	if opts.Goroutines == 0 {
+		opts.Goroutines = 8
+	}
+	if opts.Increments == 0 {
+		opts.Increments = 100
+	}
+	if opts.Increment == nil {
+		opts.Increment = TransactionalIncrement
+	}
+	counter := newConn().Node(opts.Counter)
+	if err := counter.DeleteTree(); err != nil {
+		t.Errorf("deleting counter %s: %s", counter, err)
+		return
+	}
+
+	errs := make([]error, opts.Goroutines)
+	var wg sync.WaitGroup
+	for i := range opts.Goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			conn := newConn()
+			counter := conn.Node(opts.Counter)
+			for range opts.Increments {
+				if err := opts.Increment(conn, counter); err != nil {
+					errs[i] = fmt.Errorf("goroutine %d: %w", i, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			t.Errorf("%s", err)
+		}
+	}
+
+	want := opts.Goroutines * opts.Increments
+	val, err := counter.Get("0")
+	if err != nil {
+		t.Errorf("reading counter %s: %s", counter, err)
+		return
+	}
+	if got, _ := strconv.Atoi(val); got != want {
+		t.Errorf("counter %s is %s after %d goroutines each made %d increments, want %d", counter, val, opts.Goroutines,
+			opts.Increments, want)
+	}
+}
@@ -0,0 +1,96 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package ydbtest
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+
+	"lang.yottadb.com/go/yottadb/v2"
+)
+
+// failRecorder is a testing.TB that records failures instead of failing the test, so that a test can check that
+// RunConcurrencyTest() detects them.
+type failRecorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *failRecorder) Helper() {}
+
+func (r *failRecorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, format)
+}
+
+// barrier blocks each of n goroutines in wait() until all n have called it, and may then be used again.
+type barrier struct {
+	mu         sync.Mutex
+	cond       *sync.Cond
+	n, waiting int
+	generation int
+}
+
+func newBarrier(n int) *barrier {
+	b := &barrier{n: n}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *barrier) wait() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	generation := b.generation
+	b.waiting++
+	if b.waiting == b.n {
+		b.waiting = 0
+		b.generation++
+		b.cond.Broadcast()
+		return
+	}
+	for generation == b.generation {
+		b.cond.Wait()
+	}
+}
+
+// brokenIncrement returns an IncrementFunc for the given number of goroutines that reads and sets counter without a
+// transaction. Every goroutine reads the counter before any sets it, and all set it before any reads it again, so
+// each round of increments by all the goroutines increases the counter by only 1.
+func brokenIncrement(goroutines int) IncrementFunc {
+	b := newBarrier(goroutines)
+	return func(conn *yottadb.Conn, counter *yottadb.Node) error {
+		val, err := counter.Get("0")
+		b.wait()
+		if err == nil {
+			count, _ := strconv.Atoi(val)
+			err = counter.Set(strconv.Itoa(count + 1))
+		}
+		b.wait() // wait even after an error so that the other goroutines are not left waiting
+		return err
+	}
+}
+
+// Test that RunConcurrencyTest passes transactional increments and detects increments that are not atomic.
+func TestRunConcurrencyTest(t *testing.T) {
+	RunConcurrencyTest(t, yottadb.NewConn, ConcurrencyOptions{})
+
+	recorder := &failRecorder{TB: t}
+	opts := ConcurrencyOptions{Counter: "^ydbtestBroken", Goroutines: 4, Increments: 50, Increment: brokenIncrement(4)}
+	RunConcurrencyTest(recorder, yottadb.NewConn, opts)
+	if len(recorder.errors) == 0 {
+		t.Errorf("a non-atomic increment was not detected")
+	}
+	if val, _ := yottadb.NewConn().Node(opts.Counter).Get(); val != strconv.Itoa(opts.Increments) {
+		t.Errorf("got broken counter %s, want %d", val, opts.Increments)
+	}
+}
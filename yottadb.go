@@ -18,6 +18,11 @@
 
 package yottadb
 
+import (
+	"fmt"
+	"os"
+)
+
 // #cgo pkg-config: yottadb
 // #include "libyottadb.h"
 import "C"
@@ -38,3 +43,43 @@ const MinimumGoRelease string = "go1.24"
 //go:generate ./scripts/gen_error_codes.sh
 
 const InitialBufSize = 128 // Initial size allocated to store return value of ydb_get()
+
+// InitFromEnv initialises the YottaDB engine and applies to it the environment variables that configure where data and
+// code are found, which are normally set by sourcing `ydb_env_set`. This helps containerized deployments that set these
+// variables in the process environment rather than in a login shell.
+//   - ydb_gbldir (or its older name gtmgbldir) is required, and sets $ZGBLDIR, the global directory that maps globals to database files.
+//   - ydb_routines (or gtmroutines), if set, sets $ZROUTINES, the search path for M routines.
+//
+// Other variables such as ydb_dist, ydb_chset and ydb_tmp are read by the engine only when it initialises, so they
+// must be set before the first call to YottaDB by this process.
+// Since environment variables are applied to the engine's intrinsic special variables, InitFromEnv may also be called
+// later to apply changes to the environment.
+func InitFromEnv() error {
+	gbldir := getenv("ydb_gbldir", "gtmgbldir")
+	if gbldir == "" {
+		return fmt.Errorf("YDB: environment variable ydb_gbldir must be set to the global directory file (e.g. by sourcing ydb_env_set)")
+	}
+	if ret := C.ydb_init(); ret != C.YDB_OK {
+		return Error(int(ret), fmt.Sprintf("YDB: ydb_init() failed with error code %d", ret))
+	}
+	conn := NewConn()
+	if err := conn.Node("$ZGBLDIR").Set(gbldir); err != nil {
+		return fmt.Errorf("YDB: could not set $ZGBLDIR from ydb_gbldir: %w", err)
+	}
+	if routines := getenv("ydb_routines", "gtmroutines"); routines != "" {
+		if err := conn.Node("$ZROUTINES").Set(routines); err != nil {
+			return fmt.Errorf("YDB: could not set $ZROUTINES from ydb_routines: %w", err)
+		}
+	}
+	return nil
+}
+
+// getenv returns the value of the first of the given environment variables that is set, or "" if none are set.
+func getenv(names ...string) string {
+	for _, name := range names {
+		if val := os.Getenv(name); val != "" {
+			return val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+/****************************************************************
+ *								*
+ * Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.	*
+ * All rights reserved.						*
+ *								*
+ *	This source code contains the intellectual property	*
+ *	of its copyright holder(s), and is made available	*
+ *	under a license.  If you do not know the terms of	*
+ *	the license, please stop and do not read further.	*
+ *								*
+ ****************************************************************/
+
+// C definitions shared by the cgo preambles of the Go files in this package.
+
+#ifndef YDBGO_H
+#define YDBGO_H
+
+#include <stddef.h>
+
+#include "libyottadb.h"
+
+// Create a thread-specific 'connection' object for calling the YottaDB API.
+typedef struct conn {
+	uint64_t tptoken;	// place to store tptoken for thread-safe ydb_*_st() function calls
+	ydb_buffer_t errstr;	// space for YottaDB to return an error string
+	ydb_buffer_t value;	// temporary space to store in or out value for get/set
+	ydb_buffer_t subs[YDB_MAX_SUBS];	// space for subscripts returned by ydb_node_next_st(); allocated on first use
+} conn;
+
+// Create a representation of a database node, including a cache of its subscript strings for fast calls to the YottaDB API.
+typedef struct node {
+	conn *conn;
+	int len;		// number of buffers[] allocated to store subscripts/strings
+	int datasize;		// length of string `data` field (all strings and subscripts concatenated)
+	int mutable;		// whether the node is mutable (these are only emitted by node iterators)
+	ydb_buffer_t buffers[1];	// first of an array of buffers (typically varname)
+	ydb_buffer_t buffersn[];	// rest of array
+	// char *data;		// stored after `buffers` (however large they are), which point into this data
+} node;
+
+// Node methods index buffers[] beyond its declared size to reach buffersn[], and allocate sizeof(node) plus one
+// ydb_buffer_t per subscript, so buffersn[] must directly follow buffers[0] with no padding, even at the end of node.
+_Static_assert(offsetof(node, buffersn) == offsetof(node, buffers) + sizeof(ydb_buffer_t), "node.buffersn[] must follow buffers[0]");
+_Static_assert(sizeof(node) == offsetof(node, buffersn), "node must not have padding after buffers[0]");
+
+#endif
@@ -0,0 +1,66 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Test that InitFromEnv applies environment variables to the engine's intrinsic special variables.
+func TestInitFromEnv(t *testing.T) {
+	conn := NewConn()
+	gbldir, routines := conn.Node("$ZGBLDIR"), conn.Node("$ZROUTINES")
+	zgbldir, err := gbldir.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	zroutines, err := routines.Get()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer gbldir.Set(zgbldir)
+	defer routines.Set(zroutines)
+
+	t.Run("Apply", func(t *testing.T) {
+		// Use a copy of the global directory so that $ZGBLDIR visibly changes yet still maps to the same databases
+		dir := t.TempDir()
+		gld, err := os.ReadFile(os.ExpandEnv(zgbldir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		copied := filepath.Join(dir, "copy.gld")
+		if err := os.WriteFile(copied, gld, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("ydb_gbldir", copied)
+		t.Setenv("ydb_routines", dir)
+		if err := InitFromEnv(); err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := gbldir.Get(); got != copied {
+			t.Errorf("got $ZGBLDIR=%s, want %s", got, copied)
+		}
+		if got, _ := routines.Get(); got != dir {
+			t.Errorf("got $ZROUTINES=%s, want %s", got, dir)
+		}
+	})
+	t.Run("Missing", func(t *testing.T) {
+		t.Setenv("ydb_gbldir", "")
+		t.Setenv("gtmgbldir", "")
+		if err := InitFromEnv(); err == nil {
+			t.Errorf("got no error with ydb_gbldir unset")
+		}
+	})
+}
@@ -0,0 +1,62 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+// Convert strings to and from ZWRITE format.
+
+package yottadb
+
+import (
+	"unsafe"
+)
+
+/* #include "libyottadb.h"
+#include "yottadb.h"
+*/
+import "C"
+
+// Str2Zwr returns s in ZWRITE format, the form in which M's ZWRITE command displays values: a canonical number as is,
+// and any other string quoted, with non-printable characters written as $C() expressions.
+func (conn *Conn) Str2Zwr(s string) (string, error) {
+	return conn.convertZwr(s, false)
+}
+
+// Zwr2Str returns the string represented by zwr in ZWRITE format. It is the inverse of Str2Zwr().
+func (conn *Conn) Zwr2Str(zwr string) (string, error) {
+	return conn.convertZwr(zwr, true)
+}
+
+// convertZwr converts s to ZWRITE format, or from it if reverse is true.
+func (conn *Conn) convertZwr(s string, reverse bool) (string, error) {
+	cconn := conn.c
+	in := C.ydb_buffer_t{buf_addr: C.CString(s), len_used: C.uint(len(s)), len_alloc: C.uint(len(s))}
+	defer C.free(unsafe.Pointer(in.buf_addr))
+	out := &cconn.value
+	for {
+		var ret C.int
+		if reverse {
+			ret = C.ydb_zwr2str_st(cconn.tptoken, &cconn.errstr, &in, out)
+		} else {
+			ret = C.ydb_str2zwr_st(cconn.tptoken, &cconn.errstr, &in, out)
+		}
+		if ret == C.YDB_ERR_INVSTRLEN && out == &cconn.value {
+			// The result is too long for conn.value: retry with a temporary buffer of the length needed
+			size := out.len_used
+			out = &C.ydb_buffer_t{buf_addr: (*C.char)(C.malloc(C.size_t(size))), len_alloc: size}
+			defer C.free(unsafe.Pointer(out.buf_addr))
+			continue
+		}
+		if ret != C.YDB_OK {
+			return "", conn.Error(ret)
+		}
+		return C.GoStringN(out.buf_addr, C.int(out.len_used)), nil
+	}
+}
@@ -0,0 +1,40 @@
+//////////////////////////////////////////////////////////////////
+//
+// Copyright (c) 2025 YottaDB LLC and/or its subsidiaries.
+// All rights reserved.
+//
+//	This source code contains the intellectual property
+//	of its copyright holder(s), and is made available
+//	under a license.  If you do not know the terms of
+//	the license, please stop and do not read further.
+//
+//////////////////////////////////////////////////////////////////
+
+package yottadb
+
+import (
+	"strings"
+	"testing"
+)
+
+// Test converting strings to and from ZWRITE format.
+func TestZwr(t *testing.T) {
+	conn := NewConn()
+	long := strings.Repeat("\x00", 300000) // its ZWRITE format is longer than conn.value
+	for _, s := range []string{"", "abc", "-1.5", `say "hi"`, "a\x01b", long} {
+		zwr, err := conn.Str2Zwr(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := conn.Zwr2Str(zwr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != s {
+			t.Errorf("got %q from %q, want %q", got, zwr, s)
+		}
+	}
+	if zwr, _ := conn.Str2Zwr(`say "hi"`); zwr != `"say ""hi"""` {
+		t.Errorf("got %s, want %s", zwr, `"say ""hi"""`)
+	}
+}